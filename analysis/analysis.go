@@ -0,0 +1,136 @@
+// Package analysis contains static checks which can be run over a parsed Monkey program.
+package analysis
+
+import "github.com/ollybritton/monkey/ast"
+
+// binding is a name bound in a scope, either by a let statement or as a function parameter.
+type binding struct {
+	name      string
+	parameter bool
+	used      bool
+}
+
+// scope holds the names that are bound at one level of nesting. Block statements and function bodies create new scopes.
+type scope struct {
+	outer    *scope
+	bindings map[string]*binding
+}
+
+func newScope(outer *scope) *scope {
+	return &scope{outer: outer, bindings: make(map[string]*binding)}
+}
+
+// lookup finds the binding for a name, searching the outer scopes if it isn't bound in this one.
+func (s *scope) lookup(name string) *binding {
+	if b, ok := s.bindings[name]; ok {
+		return b
+	}
+
+	if s.outer != nil {
+		return s.outer.lookup(name)
+	}
+
+	return nil
+}
+
+// pendingFunction is a function literal whose body is yet to be analysed, along with the scope it was defined in.
+type pendingFunction struct {
+	fn    *ast.FunctionLiteral
+	scope *scope
+}
+
+// analyser keeps track of every binding made in the program, and the function bodies that are yet to be analysed.
+type analyser struct {
+	bindings []*binding
+	pending  []pendingFunction
+}
+
+// UnusedLets returns the names bound by let statements which are never referenced within their scope, in the order
+// that they are declared. A name which is bound again in the same scope before being used is reported each time.
+//
+// Function bodies are analysed after the scope they are defined in, since they can refer to names which are bound
+// after the function itself, such as in recursive or mutually recursive functions.
+func UnusedLets(program *ast.Program) []string {
+	a := &analyser{}
+	a.statements(program.Statements, newScope(nil))
+
+	for len(a.pending) > 0 {
+		next := a.pending[0]
+		a.pending = a.pending[1:]
+		a.function(next.fn, next.scope)
+	}
+
+	unused := []string{}
+	for _, b := range a.bindings {
+		if !b.parameter && !b.used {
+			unused = append(unused, b.name)
+		}
+	}
+
+	return unused
+}
+
+// declare binds a name in the given scope.
+func (a *analyser) declare(s *scope, name string, parameter bool) {
+	b := &binding{name: name, parameter: parameter}
+	s.bindings[name] = b
+	a.bindings = append(a.bindings, b)
+}
+
+// statements analyses a list of statements inside the given scope.
+func (a *analyser) statements(stmts []ast.Statement, s *scope) {
+	for _, stmt := range stmts {
+		a.node(stmt, s)
+	}
+}
+
+// function analyses the body of a function literal in a new scope containing its parameters.
+func (a *analyser) function(fn *ast.FunctionLiteral, outer *scope) {
+	s := newScope(outer)
+	for i, p := range fn.Parameters {
+		// Default values are evaluated after the earlier parameters have been bound, so they can refer to them.
+		if i < len(fn.Defaults) && fn.Defaults[i] != nil {
+			a.node(fn.Defaults[i], s)
+		}
+
+		a.declare(s, p.Value, true)
+	}
+
+	if fn.Body != nil {
+		a.statements(fn.Body.Statements, s)
+	}
+}
+
+// node analyses a single node and its children inside the given scope.
+func (a *analyser) node(node ast.Node, s *scope) {
+	ast.Walk(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.LetStatement:
+			// The value is analysed before the name is bound, so "let a = a + 1" refers to an outer a.
+			a.node(n.Value, s)
+			if n.Name != nil {
+				a.declare(s, n.Name.Value, false)
+			}
+
+			if n.Pattern != nil {
+				for _, e := range n.Pattern.Elements {
+					a.declare(s, e.Value, false)
+				}
+			}
+
+			return false
+		case *ast.Identifier:
+			if b := s.lookup(n.Value); b != nil {
+				b.used = true
+			}
+		case *ast.BlockStatement:
+			a.statements(n.Statements, newScope(s))
+			return false
+		case *ast.FunctionLiteral:
+			a.pending = append(a.pending, pendingFunction{fn: n, scope: s})
+			return false
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,44 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/parser"
+)
+
+func TestUnusedLets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let a = 1; a;", []string{}},
+		{"let a = 1;", []string{"a"}},
+		{"let a = 1; let b = a;", []string{"b"}},
+		{"let a = 1; let a = 2; a;", []string{"a"}},
+		{"let a = 1; if (true) { let a = 2; a; }", []string{"a"}},
+		{"let a = 1; if (true) { let a = 2; } a;", []string{"a"}},
+		{"let a = 1; let f = fn(a) { a }; f(2);", []string{"a"}},
+		{"let f = fn(x) { let y = x; x }; f(1);", []string{"y"}},
+		{"let f = fn() { g() }; let g = fn() { 1 }; f();", []string{}},
+		{"let a = 1; let a = a + 1; a;", []string{}},
+		{"let add = fn(x) { fn(y) { x + y } }; add(1)(2);", []string{}},
+		{"let [a, b] = [1, 2]; a;", []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser had errors for %q: %v", tt.input, p.Errors())
+		}
+
+		unused := UnusedLets(program)
+		if !reflect.DeepEqual(unused, tt.expected) {
+			t.Errorf("wrong unused lets for %q. got=%v, want=%v", tt.input, unused, tt.expected)
+		}
+	}
+}
@@ -17,6 +17,13 @@ type Node interface {
 
 	// String is a string representation of the node.
 	String() string
+
+	// Pos is the position of the first character of the node in the source input.
+	Pos() token.Position
+
+	// End is the position just after the last token of the node. Closing brackets and semicolons aren't stored in the
+	// tree, so they aren't included.
+	End() token.Position
 }
 
 // Statement is a node which holds a statement. A statement is that it doesn't produce a value, unlike expressions, which do.
@@ -58,17 +65,21 @@ func (p *Program) String() string {
 	return out.String()
 }
 
-// LetStatement represents a let statement, such as "let a = 1" or "let q = 5 * add(1,2)"
+// LetStatement represents a let statement, such as "let a = 1" or "let q = 5 * add(1,2)". Const statements such as
+// "const b = 2" are also represented as a LetStatement, with Constant set to true.
 // The general form is "let <ident> = <expression>"
+// When the statement destructures an array, such as "let [a, b] = pair", Pattern holds the names and Name is nil.
 type LetStatement struct {
-	Token token.Token // the token.LET token
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // the token.LET or token.CONST token
+	Name     *Identifier
+	Pattern  *ArrayPattern
+	Value    Expression
+	Constant bool
 }
 
 func (ls *LetStatement) statementNode() {}
 
-// TokenLiteral returns the literal value of the LET token. This is will always be "let"
+// TokenLiteral returns the literal value of the LET or CONST token. This is will always be "let" or "const"
 func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
 
 // String returns the string representation of that let statement.
@@ -76,7 +87,13 @@ func (ls *LetStatement) String() string {
 	out := bytes.Buffer{}
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
+
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -104,6 +121,26 @@ func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
 // String returns the name of the identifier.
 func (i *Identifier) String() string { return i.Value }
 
+// ArrayPattern represents the list of names on the left of a destructuring let statement, such as the "[a, b]" in
+// "let [a, b] = pair;".
+type ArrayPattern struct {
+	Token    token.Token // the '[' token
+	Elements []*Identifier
+}
+
+// TokenLiteral returns the literal value of the '[' token.
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+
+// String returns the pattern as a string, such as "[a, b]".
+func (ap *ArrayPattern) String() string {
+	elements := []string{}
+	for _, e := range ap.Elements {
+		elements = append(elements, e.String())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
 // ReturnStatement represents a return statement, such as "return 0" or "return add(15)"
 // The general form is "return <expression>"
 type ReturnStatement struct {
@@ -176,6 +213,136 @@ func (bs *BlockStatement) String() string {
 	return out.String()
 }
 
+// ForStatement represents a C-style for loop, such as "for (let i = 0; i < 10; i = i + 1) { puts(i) }".
+// The general form is "for (<init>; <condition>; <post>) <block>", and any of init, condition and post can be left out.
+type ForStatement struct {
+	Token     token.Token // the 'for' token
+	Init      Statement
+	Condition Expression
+	Post      Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the 'for' token, which is always "for".
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+
+// String returns the string representation of the for loop.
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+
+	if fs.Init != nil {
+		out.WriteString(strings.TrimSuffix(fs.Init.String(), ";"))
+	}
+
+	out.WriteString("; ")
+
+	if fs.Condition != nil {
+		out.WriteString(fs.Condition.String())
+	}
+
+	out.WriteString("; ")
+
+	if fs.Post != nil {
+		out.WriteString(fs.Post.String())
+	}
+
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// DoWhileStatement represents a loop which runs its body before checking its condition, such as
+// "do { i = i + 1 } while (i < 10);". The body is always run at least once.
+type DoWhileStatement struct {
+	Token     token.Token // the 'do' token
+	Body      *BlockStatement
+	Condition Expression
+}
+
+func (dws *DoWhileStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the 'do' token, which is always "do".
+func (dws *DoWhileStatement) TokenLiteral() string { return dws.Token.Literal }
+
+// String returns the string representation of the do-while loop.
+func (dws *DoWhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("do ")
+	out.WriteString(dws.Body.String())
+	out.WriteString(" while (")
+	out.WriteString(dws.Condition.String())
+	out.WriteString(");")
+
+	return out.String()
+}
+
+// ForInStatement represents a loop over the items in an array, string or hash, such as "for x in arr { puts(x) }".
+// The general form is "for [<key>,] <value> in <expression> <block>". Key is nil if only one name is given.
+type ForInStatement struct {
+	Token    token.Token // the 'for' token
+	Key      *Identifier
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fis *ForInStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the 'for' token, which is always "for".
+func (fis *ForInStatement) TokenLiteral() string { return fis.Token.Literal }
+
+// String returns the string representation of the loop.
+func (fis *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+
+	if fis.Key != nil {
+		out.WriteString(fis.Key.String())
+		out.WriteString(", ")
+	}
+
+	out.WriteString(fis.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(fis.Iterable.String())
+	out.WriteString(" ")
+	out.WriteString(fis.Body.String())
+
+	return out.String()
+}
+
+// BreakStatement represents a "break" statement, which stops the loop it is inside.
+type BreakStatement struct {
+	Token token.Token // the 'break' token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the 'break' token, which is always "break".
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+
+// String returns the string representation of the break statement.
+func (bs *BreakStatement) String() string { return bs.TokenLiteral() + ";" }
+
+// ContinueStatement represents a "continue" statement, which skips to the next iteration of the loop it is inside.
+type ContinueStatement struct {
+	Token token.Token // the 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+// TokenLiteral returns the literal value of the 'continue' token, which is always "continue".
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+
+// String returns the string representation of the continue statement.
+func (cs *ContinueStatement) String() string { return cs.TokenLiteral() + ";" }
+
 // IntegerLiteral represents an integer in the AST, like "5".
 type IntegerLiteral struct {
 	Token token.Token // the token.INT type
@@ -248,6 +415,76 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+// AssignExpression represents assigning a new value to something which already exists, such as "a = 5" or
+// "hash["key"] = 10". The general form is "<target> = <expression>"
+type AssignExpression struct {
+	Token  token.Token // the '=' token
+	Target Expression
+	Value  Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the '=' token.
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+
+// String returns the assignment as a string, wrapping it in brackets.
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" = ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// CompoundAssignExpression represents assigning to something the result of an arithmetic operator applied to its
+// current value, such as "a += 5" or "hash["key"] *= 2". The target is only evaluated once.
+type CompoundAssignExpression struct {
+	Token    token.Token // the '+=', '-=', '*=' or '/=' token
+	Target   Expression
+	Operator string // the arithmetic operator, without the '='
+	Value    Expression
+}
+
+func (ce *CompoundAssignExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the operator token.
+func (ce *CompoundAssignExpression) TokenLiteral() string { return ce.Token.Literal }
+
+// String returns the assignment as a string, wrapping it in brackets.
+func (ce *CompoundAssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Target.String())
+	out.WriteString(" " + ce.Operator + "= ")
+	out.WriteString(ce.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// PostfixExpression represents incrementing or decrementing a variable, such as "i++" or "i--".
+type PostfixExpression struct {
+	Token    token.Token // the '++' or '--' token
+	Target   *Identifier
+	Operator string
+}
+
+func (pe *PostfixExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the operator token.
+func (pe *PostfixExpression) TokenLiteral() string { return pe.Token.Literal }
+
+// String returns the expression as a string, wrapping it in brackets.
+func (pe *PostfixExpression) String() string {
+	return "(" + pe.Target.String() + pe.Operator + ")"
+}
+
 // IfExpression represents an if-else statement in the AST.
 type IfExpression struct {
 	Token       token.Token // the 'if' token.
@@ -278,10 +515,126 @@ func (ife *IfExpression) String() string {
 	return out.String()
 }
 
+// TernaryExpression represents a conditional expression using the "?" and ":" operators, such as "a > b ? a : b".
+type TernaryExpression struct {
+	Token       token.Token // the '?' token.
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (te *TernaryExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the '?' token.
+func (te *TernaryExpression) TokenLiteral() string { return te.Token.Literal }
+
+// String returns the ternary expression as a string, wrapping it in brackets.
+func (te *TernaryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(te.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(te.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(te.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// SwitchExpression represents a switch, such as "switch (x) { case 1: a; default: b }". The body of the first case
+// whose value is equal to the subject is evaluated, and there is no fallthrough into the cases after it. The default
+// case is only used if none of the cases match, wherever it appears.
+type SwitchExpression struct {
+	Token   token.Token // the 'switch' token.
+	Subject Expression
+	Cases   []*SwitchCase
+	Default *BlockStatement // nil if there is no default case
+}
+
+func (se *SwitchExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the 'switch' token.
+func (se *SwitchExpression) TokenLiteral() string { return se.Token.Literal }
+
+// String returns the switch expression as a string.
+func (se *SwitchExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("switch")
+	out.WriteString(se.Subject.String())
+	out.WriteString(" {")
+
+	for _, c := range se.Cases {
+		out.WriteString(" ")
+		out.WriteString(c.String())
+	}
+
+	if se.Default != nil {
+		out.WriteString(" default: ")
+		out.WriteString(se.Default.String())
+	}
+
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// SwitchCase is a single case inside a switch expression, such as "case 1: a;". A case can list several values, such
+// as "case 1, 2, 3: a;", and matches if the subject is equal to any of them.
+type SwitchCase struct {
+	Token  token.Token // the 'case' token.
+	Values []Expression
+	Body   *BlockStatement
+}
+
+// TokenLiteral returns the literal value of the 'case' token.
+func (sc *SwitchCase) TokenLiteral() string { return sc.Token.Literal }
+
+// String returns the case as a string.
+func (sc *SwitchCase) String() string {
+	values := []string{}
+	for _, v := range sc.Values {
+		values = append(values, v.String())
+	}
+
+	return "case " + strings.Join(values, ", ") + ": " + sc.Body.String()
+}
+
+// TryExpression represents a try/catch, such as "try { risky() } catch (e) { puts(e) }". If the try block produces an
+// error, the catch block is evaluated with the error message bound to the parameter.
+type TryExpression struct {
+	Token     token.Token // the 'try' token.
+	Body      *BlockStatement
+	Parameter *Identifier
+	Catch     *BlockStatement
+}
+
+func (te *TryExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the 'try' token.
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+
+// String returns the try expression as a string.
+func (te *TryExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("try ")
+	out.WriteString(te.Body.String())
+	out.WriteString(" catch(")
+	out.WriteString(te.Parameter.String())
+	out.WriteString(") ")
+	out.WriteString(te.Catch.String())
+
+	return out.String()
+}
+
 // FunctionLiteral represents a function in the AST.
 type FunctionLiteral struct {
 	Token      token.Token // the 'fn' token.
 	Parameters []*Identifier
+	Defaults   []Expression // the default value of each parameter, or nil for parameters without one
 	Body       *BlockStatement
 }
 
@@ -294,16 +647,57 @@ func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
+	out.WriteString(fl.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(ParametersString(fl.Parameters, fl.Defaults))
+	out.WriteString(")")
+	out.WriteString(fl.Body.String())
+
+	return out.String()
+}
+
+// ParametersString returns the string representation of a list of parameters and their default values, such as
+// "x, y = 10". defaults may be shorter than params, or nil, if the later parameters don't have default values.
+func ParametersString(params []*Identifier, defaults []Expression) string {
+	out := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			out = append(out, p.String()+" = "+defaults[i].String())
+		} else {
+			out = append(out, p.String())
+		}
+	}
+
+	return strings.Join(out, ", ")
+}
+
+// MacroLiteral represents a macro definition in the AST, such as "macro(a, b) { quote(unquote(b) - unquote(a)) }".
+// Macros look like functions, but they are passed their arguments unevaluated and are expanded before the program runs.
+type MacroLiteral struct {
+	Token      token.Token // the 'macro' token.
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode() {}
+
+// TokenLiteral returns the literal value of the 'macro' token, which is always "macro".
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+
+// String returns the string representation of the macro.
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+
 	params := []string{}
-	for _, p := range fl.Parameters {
+	for _, p := range ml.Parameters {
 		params = append(params, p.String())
 	}
 
-	out.WriteString(fl.TokenLiteral())
+	out.WriteString(ml.TokenLiteral())
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
-	out.WriteString(fl.Body.String())
+	out.WriteString(ml.Body.String())
 
 	return out.String()
 }
@@ -338,6 +732,19 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+// NullLiteral represents the "null" keyword in the AST.
+type NullLiteral struct {
+	Token token.Token // the token.NULL token
+}
+
+func (nl *NullLiteral) expressionNode() {}
+
+// TokenLiteral returns the literal value of the token, which is always "null".
+func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Literal }
+
+// String returns the string representation of null.
+func (nl *NullLiteral) String() string { return nl.Token.Literal }
+
 // StringLiteral represents a string in the AST.
 type StringLiteral struct {
 	Token token.Token
@@ -402,6 +809,44 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// SliceExpression represents taking a range of elements out of an array or string, such as arr[1:3]. Either bound can be
+// omitted, in which case Low or High will be nil.
+type SliceExpression struct {
+	Token token.Token // The '[' token.
+	Left  Expression
+	Low   Expression
+	High  Expression
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+// TokenLiteral returns the literal value of the '[' token.
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+
+// String returns the string representation of the slice, leaving out any omitted bounds.
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+
+	out.WriteString(":")
+
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+
+	out.WriteString("]")
+	out.WriteString(")")
+
+	return out.String()
+}
+
 // HashLiteral represents a map-like data structure.
 type HashLiteral struct {
 	Token token.Token
@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+// Fold is an optional optimisation pass which replaces expressions made only of integer and boolean literals with the
+// literal they evaluate to, so "2 + 3 * 4" becomes "14". Anything involving an identifier or a call is left alone, as is
+// division by zero or an overflow so that the error still happens when the program is run. Only the arithmetic operators
+// +, -, * and /, the comparisons and the ! and - prefixes are folded. The tree is changed in place and returned.
+func Fold(node Node) Node {
+	return Modify(node, func(node Node) Node {
+		switch node := node.(type) {
+		case *InfixExpression:
+			if folded := foldInfix(node); folded != nil {
+				return folded
+			}
+		case *PrefixExpression:
+			if folded := foldPrefix(node); folded != nil {
+				return folded
+			}
+		}
+
+		return node
+	})
+}
+
+// foldInfix returns the literal an infix expression evaluates to, or nil if it can't be folded.
+func foldInfix(node *InfixExpression) Expression {
+	switch left := node.Left.(type) {
+	case *IntegerLiteral:
+		right, ok := node.Right.(*IntegerLiteral)
+		if !ok {
+			return nil
+		}
+
+		switch node.Operator {
+		case "+", "-", "*":
+			// The result is computed with big integers so that an overflow, which is an error when the program runs, is
+			// not folded.
+			result := new(big.Int)
+			a, b := big.NewInt(left.Value), big.NewInt(right.Value)
+
+			switch node.Operator {
+			case "+":
+				result.Add(a, b)
+			case "-":
+				result.Sub(a, b)
+			case "*":
+				result.Mul(a, b)
+			}
+
+			if !result.IsInt64() {
+				return nil
+			}
+
+			return newFoldedInteger(node, result.Int64())
+		case "/":
+			if right.Value == 0 || (left.Value == math.MinInt64 && right.Value == -1) {
+				return nil
+			}
+
+			return newFoldedInteger(node, left.Value/right.Value)
+		case "<":
+			return newFoldedBoolean(node, left.Value < right.Value)
+		case ">":
+			return newFoldedBoolean(node, left.Value > right.Value)
+		case "<=":
+			return newFoldedBoolean(node, left.Value <= right.Value)
+		case ">=":
+			return newFoldedBoolean(node, left.Value >= right.Value)
+		case "==":
+			return newFoldedBoolean(node, left.Value == right.Value)
+		case "!=":
+			return newFoldedBoolean(node, left.Value != right.Value)
+		}
+	case *Boolean:
+		right, ok := node.Right.(*Boolean)
+		if !ok {
+			return nil
+		}
+
+		switch node.Operator {
+		case "==":
+			return newFoldedBoolean(node, left.Value == right.Value)
+		case "!=":
+			return newFoldedBoolean(node, left.Value != right.Value)
+		}
+	}
+
+	return nil
+}
+
+// foldPrefix returns the literal a prefix expression evaluates to, or nil if it can't be folded.
+func foldPrefix(node *PrefixExpression) Expression {
+	switch right := node.Right.(type) {
+	case *IntegerLiteral:
+		if node.Operator == "-" && right.Value != math.MinInt64 {
+			return newFoldedInteger(node, -right.Value)
+		}
+	case *Boolean:
+		if node.Operator == "!" {
+			return newFoldedBoolean(node, !right.Value)
+		}
+	}
+
+	return nil
+}
+
+// newFoldedInteger creates the integer literal which replaces a folded expression, keeping its position.
+func newFoldedInteger(node Expression, value int64) *IntegerLiteral {
+	return &IntegerLiteral{Token: foldedToken(node, token.INT, strconv.FormatInt(value, 10)), Value: value}
+}
+
+// newFoldedBoolean creates the boolean literal which replaces a folded expression, keeping its position.
+func newFoldedBoolean(node Expression, value bool) *Boolean {
+	if value {
+		return &Boolean{Token: foldedToken(node, token.TRUE, "true"), Value: true}
+	}
+
+	return &Boolean{Token: foldedToken(node, token.FALSE, "false"), Value: false}
+}
+
+// foldedToken creates the token for a folded literal, spanning the expression it replaces.
+func foldedToken(node Expression, tokenType token.TokenType, literal string) token.Token {
+	return token.Token{Type: tokenType, Literal: literal, Pos: node.Pos(), End: node.End()}
+}
@@ -0,0 +1,87 @@
+package ast
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+func TestFold(t *testing.T) {
+	// The expressions below are built without positions, so the folded literals don't have one either.
+	integer := func(value int64) *IntegerLiteral { return newFoldedInteger(&NullLiteral{}, value) }
+	boolean := func(value bool) *Boolean { return newFoldedBoolean(&NullLiteral{}, value) }
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	infix := func(left Expression, operator string, right Expression) *InfixExpression {
+		return &InfixExpression{Token: token.Token{Literal: operator}, Left: left, Operator: operator, Right: right}
+	}
+	prefix := func(operator string, right Expression) *PrefixExpression {
+		return &PrefixExpression{Token: token.Token{Literal: operator}, Operator: operator, Right: right}
+	}
+
+	tests := []struct {
+		input    Expression
+		expected string
+	}{
+		// 2 + 3 * 4
+		{infix(integer(2), "+", infix(integer(3), "*", integer(4))), "14"},
+		// (10 - 4) / 3 > 1
+		{infix(infix(infix(integer(10), "-", integer(4)), "/", integer(3)), ">", integer(1)), "true"},
+		// -(2 + 3)
+		{prefix("-", infix(integer(2), "+", integer(3))), "-5"},
+		// !(1 == 2)
+		{prefix("!", infix(integer(1), "==", integer(2))), "true"},
+		// 2 <= 2
+		{infix(integer(2), "<=", integer(2)), "true"},
+		// 1 >= 2
+		{infix(integer(1), ">=", integer(2)), "false"},
+		// true != false
+		{infix(boolean(true), "!=", boolean(false)), "true"},
+		// x + 1
+		{infix(ident("x"), "+", integer(1)), "(x + 1)"},
+		// x + (1 + 2)
+		{infix(ident("x"), "+", infix(integer(1), "+", integer(2))), "(x + 3)"},
+		// 1 / 0
+		{infix(integer(1), "/", integer(0)), "(1 / 0)"},
+		// 1 + true
+		{infix(integer(1), "+", boolean(true)), "(1 + true)"},
+		// 9223372036854775807 + 1
+		{infix(integer(math.MaxInt64), "+", integer(1)), "(9223372036854775807 + 1)"},
+		// -9223372036854775807 - 2
+		{infix(integer(-math.MaxInt64), "-", integer(2)), "(-9223372036854775807 - 2)"},
+		// 4611686018427387904 * 2
+		{infix(integer(1<<62), "*", integer(2)), "(4611686018427387904 * 2)"},
+		// -9223372036854775808 / -1
+		{infix(integer(math.MinInt64), "/", integer(-1)), "(-9223372036854775808 / -1)"},
+		// -9223372036854775808 / 1
+		{infix(integer(math.MinInt64), "/", integer(1)), "-9223372036854775808"},
+		// 2 ** 3 is not folded
+		{infix(integer(2), "**", integer(3)), "(2 ** 3)"},
+	}
+
+	for _, tt := range tests {
+		program := &Program{Statements: []Statement{&ExpressionStatement{Expression: tt.input}}}
+
+		folded := Fold(program)
+		if folded.String() != tt.expected {
+			t.Errorf("Fold wrong. got=%q, want=%q", folded.String(), tt.expected)
+		}
+	}
+}
+
+func TestFoldInsideCalls(t *testing.T) {
+	f := &Identifier{Token: token.Token{Type: token.IDENT, Literal: "f"}, Value: "f"}
+	call := &CallExpression{
+		Function: f,
+		Arguments: []Expression{
+			&InfixExpression{Left: newFoldedInteger(&NullLiteral{}, 1), Operator: "+", Right: newFoldedInteger(&NullLiteral{}, 2)},
+		},
+	}
+
+	folded := Fold(&ExpressionStatement{Expression: call})
+	if folded.String() != "f(3)" {
+		t.Errorf("Fold wrong. got=%q, want=%q", folded.String(), "f(3)")
+	}
+}
@@ -0,0 +1,228 @@
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+// MarshalJSON serializes a node, and all of its children, into a JSON tree. Every node in the tree is an object with a
+// "type" field holding the name of the node's type (such as "LetStatement") alongside the fields relevant to that node.
+func MarshalJSON(node Node) ([]byte, error) {
+	return json.Marshal(nodeToJSON(node))
+}
+
+// MarshalIndentJSON is like MarshalJSON but indents the output so that it is easier to read.
+func MarshalIndentJSON(node Node, prefix, indent string) ([]byte, error) {
+	return json.MarshalIndent(nodeToJSON(node), prefix, indent)
+}
+
+// jsonNode is the JSON representation of a single node in the tree.
+type jsonNode map[string]interface{}
+
+// nodeToJSON converts a node into a jsonNode. A nil node is converted into nil, which becomes null in the output.
+func nodeToJSON(node Node) interface{} {
+	// Nodes are always pointers, and the parser can leave typed nil pointers in the tree (such as a missing else block).
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return nil
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		return jsonNode{"type": "Program", "statements": statementsToJSON(node.Statements)}
+	case *LetStatement:
+		return jsonNode{
+			"type":     "LetStatement",
+			"name":     nodeToJSON(node.Name),
+			"pattern":  nodeToJSON(node.Pattern),
+			"value":    nodeToJSON(node.Value),
+			"constant": node.Constant,
+		}
+	case *ArrayPattern:
+		elements := []interface{}{}
+		for _, e := range node.Elements {
+			elements = append(elements, nodeToJSON(e))
+		}
+
+		return jsonNode{"type": "ArrayPattern", "elements": elements}
+	case *ReturnStatement:
+		return jsonNode{"type": "ReturnStatement", "returnValue": nodeToJSON(node.ReturnValue)}
+	case *ExpressionStatement:
+		return jsonNode{"type": "ExpressionStatement", "expression": nodeToJSON(node.Expression)}
+	case *BlockStatement:
+		return jsonNode{"type": "BlockStatement", "statements": statementsToJSON(node.Statements)}
+	case *ForStatement:
+		return jsonNode{
+			"type":      "ForStatement",
+			"init":      nodeToJSON(node.Init),
+			"condition": nodeToJSON(node.Condition),
+			"post":      nodeToJSON(node.Post),
+			"body":      nodeToJSON(node.Body),
+		}
+	case *ForInStatement:
+		return jsonNode{
+			"type":     "ForInStatement",
+			"key":      nodeToJSON(node.Key),
+			"value":    nodeToJSON(node.Value),
+			"iterable": nodeToJSON(node.Iterable),
+			"body":     nodeToJSON(node.Body),
+		}
+	case *DoWhileStatement:
+		return jsonNode{"type": "DoWhileStatement", "body": nodeToJSON(node.Body), "condition": nodeToJSON(node.Condition)}
+	case *BreakStatement:
+		return jsonNode{"type": "BreakStatement"}
+	case *ContinueStatement:
+		return jsonNode{"type": "ContinueStatement"}
+	case *Identifier:
+		return jsonNode{"type": "Identifier", "value": node.Value}
+	case *IntegerLiteral:
+		return jsonNode{"type": "IntegerLiteral", "value": node.Value}
+	case *NullLiteral:
+		return jsonNode{"type": "NullLiteral"}
+	case *StringLiteral:
+		return jsonNode{"type": "StringLiteral", "value": node.Value}
+	case *Boolean:
+		return jsonNode{"type": "Boolean", "value": node.Value}
+	case *PrefixExpression:
+		return jsonNode{"type": "PrefixExpression", "operator": node.Operator, "right": nodeToJSON(node.Right)}
+	case *InfixExpression:
+		return jsonNode{
+			"type":     "InfixExpression",
+			"left":     nodeToJSON(node.Left),
+			"operator": node.Operator,
+			"right":    nodeToJSON(node.Right),
+		}
+	case *AssignExpression:
+		return jsonNode{"type": "AssignExpression", "target": nodeToJSON(node.Target), "value": nodeToJSON(node.Value)}
+	case *CompoundAssignExpression:
+		return jsonNode{
+			"type":     "CompoundAssignExpression",
+			"target":   nodeToJSON(node.Target),
+			"operator": node.Operator,
+			"value":    nodeToJSON(node.Value),
+		}
+	case *PostfixExpression:
+		return jsonNode{"type": "PostfixExpression", "target": nodeToJSON(node.Target), "operator": node.Operator}
+	case *IfExpression:
+		return jsonNode{
+			"type":        "IfExpression",
+			"condition":   nodeToJSON(node.Condition),
+			"consequence": nodeToJSON(node.Consequence),
+			"alternative": nodeToJSON(node.Alternative),
+		}
+	case *TernaryExpression:
+		return jsonNode{
+			"type":        "TernaryExpression",
+			"condition":   nodeToJSON(node.Condition),
+			"consequence": nodeToJSON(node.Consequence),
+			"alternative": nodeToJSON(node.Alternative),
+		}
+	case *SwitchExpression:
+		cases := []interface{}{}
+		for _, c := range node.Cases {
+			cases = append(cases, nodeToJSON(c))
+		}
+
+		return jsonNode{
+			"type":    "SwitchExpression",
+			"subject": nodeToJSON(node.Subject),
+			"cases":   cases,
+			"default": nodeToJSON(node.Default),
+		}
+	case *SwitchCase:
+		return jsonNode{"type": "SwitchCase", "values": expressionsToJSON(node.Values), "body": nodeToJSON(node.Body)}
+	case *TryExpression:
+		return jsonNode{
+			"type":      "TryExpression",
+			"body":      nodeToJSON(node.Body),
+			"parameter": nodeToJSON(node.Parameter),
+			"catch":     nodeToJSON(node.Catch),
+		}
+	case *FunctionLiteral:
+		params := []interface{}{}
+		for _, p := range node.Parameters {
+			params = append(params, nodeToJSON(p))
+		}
+
+		return jsonNode{
+			"type":       "FunctionLiteral",
+			"parameters": params,
+			"defaults":   expressionsToJSON(node.Defaults),
+			"body":       nodeToJSON(node.Body),
+		}
+	case *MacroLiteral:
+		params := []interface{}{}
+		for _, p := range node.Parameters {
+			params = append(params, nodeToJSON(p))
+		}
+
+		return jsonNode{"type": "MacroLiteral", "parameters": params, "body": nodeToJSON(node.Body)}
+	case *CallExpression:
+		return jsonNode{
+			"type":      "CallExpression",
+			"function":  nodeToJSON(node.Function),
+			"arguments": expressionsToJSON(node.Arguments),
+		}
+	case *ArrayLiteral:
+		return jsonNode{"type": "ArrayLiteral", "elements": expressionsToJSON(node.Elements)}
+	case *IndexExpression:
+		return jsonNode{"type": "IndexExpression", "left": nodeToJSON(node.Left), "index": nodeToJSON(node.Index)}
+	case *SliceExpression:
+		return jsonNode{
+			"type": "SliceExpression",
+			"left": nodeToJSON(node.Left),
+			"low":  nodeToJSON(node.Low),
+			"high": nodeToJSON(node.High),
+		}
+	case *HashLiteral:
+		pairs := []interface{}{}
+		for _, key := range sortedKeys(node) {
+			pairs = append(pairs, jsonNode{"key": nodeToJSON(key), "value": nodeToJSON(node.Pairs[key])})
+		}
+
+		return jsonNode{"type": "HashLiteral", "pairs": pairs}
+	}
+
+	return jsonNode{"type": "Unknown", "string": node.String()}
+}
+
+// sortedKeys returns the keys of a hash literal in the order they appear in the input, so that the output doesn't depend
+// on the order of the map. Keys without a position, or with the same one, are ordered by their string representations.
+func sortedKeys(hl *HashLiteral) []Expression {
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if pi, pj := posOf(keys[i], token.Position{}), posOf(keys[j], token.Position{}); pi != pj {
+			return after(pj, pi)
+		}
+
+		return keys[i].String() < keys[j].String()
+	})
+
+	return keys
+}
+
+// statementsToJSON converts a list of statements into a list of jsonNodes.
+func statementsToJSON(stmts []Statement) []interface{} {
+	out := []interface{}{}
+	for _, s := range stmts {
+		out = append(out, nodeToJSON(s))
+	}
+
+	return out
+}
+
+// expressionsToJSON converts a list of expressions into a list of jsonNodes.
+func expressionsToJSON(exps []Expression) []interface{} {
+	out := []interface{}{}
+	for _, e := range exps {
+		out = append(out, nodeToJSON(e))
+	}
+
+	return out
+}
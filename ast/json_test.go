@@ -0,0 +1,189 @@
+package ast
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+func TestMarshalJSONLetStatement(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "myVar"},
+					Value: "myVar",
+				},
+				Value: &IntegerLiteral{
+					Token: token.Token{Type: token.INT, Literal: "5"},
+					Value: 5,
+				},
+			},
+		},
+	}
+
+	tree := testRoundTrip(t, program)
+
+	if tree["type"] != "Program" {
+		t.Fatalf("root type wrong. got=%v", tree["type"])
+	}
+
+	stmts, ok := tree["statements"].([]interface{})
+	if !ok || len(stmts) != 1 {
+		t.Fatalf("statements wrong. got=%v", tree["statements"])
+	}
+
+	stmt := stmts[0].(map[string]interface{})
+	if stmt["type"] != "LetStatement" {
+		t.Fatalf("statement type wrong. got=%v", stmt["type"])
+	}
+
+	name := stmt["name"].(map[string]interface{})
+	if name["type"] != "Identifier" || name["value"] != "myVar" {
+		t.Errorf("name wrong. got=%v", name)
+	}
+
+	value := stmt["value"].(map[string]interface{})
+	if value["type"] != "IntegerLiteral" || value["value"] != float64(5) {
+		t.Errorf("value wrong. got=%v", value)
+	}
+}
+
+func TestMarshalJSONInfixExpression(t *testing.T) {
+	program := &Program{
+		Statements: []Statement{
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "a"},
+				Expression: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Operator: "+",
+					Left: &Identifier{
+						Token: token.Token{Type: token.IDENT, Literal: "a"},
+						Value: "a",
+					},
+					Right: &Boolean{
+						Token: token.Token{Type: token.TRUE, Literal: "true"},
+						Value: true,
+					},
+				},
+			},
+		},
+	}
+
+	tree := testRoundTrip(t, program)
+
+	stmt := tree["statements"].([]interface{})[0].(map[string]interface{})
+	if stmt["type"] != "ExpressionStatement" {
+		t.Fatalf("statement type wrong. got=%v", stmt["type"])
+	}
+
+	infix := stmt["expression"].(map[string]interface{})
+	if infix["type"] != "InfixExpression" {
+		t.Fatalf("expression type wrong. got=%v", infix["type"])
+	}
+
+	if infix["operator"] != "+" {
+		t.Errorf("operator wrong. got=%v", infix["operator"])
+	}
+
+	left := infix["left"].(map[string]interface{})
+	if left["type"] != "Identifier" || left["value"] != "a" {
+		t.Errorf("left wrong. got=%v", left)
+	}
+
+	right := infix["right"].(map[string]interface{})
+	if right["type"] != "Boolean" || right["value"] != true {
+		t.Errorf("right wrong. got=%v", right)
+	}
+}
+
+func TestMarshalJSONMissingAlternative(t *testing.T) {
+	exp := &IfExpression{
+		Token: token.Token{Type: token.IF, Literal: "if"},
+		Condition: &Boolean{
+			Token: token.Token{Type: token.TRUE, Literal: "true"},
+			Value: true,
+		},
+		Consequence: &BlockStatement{Token: token.Token{Type: token.LBRACE, Literal: "{"}},
+	}
+
+	tree := testRoundTrip(t, exp)
+
+	if tree["type"] != "IfExpression" {
+		t.Fatalf("type wrong. got=%v", tree["type"])
+	}
+
+	if tree["alternative"] != nil {
+		t.Errorf("alternative should be null. got=%v", tree["alternative"])
+	}
+}
+
+func TestMarshalJSONHashLiteralOrder(t *testing.T) {
+	// A column of 0 gives the key no position at all.
+	key := func(value string, column int) *StringLiteral {
+		var pos token.Position
+		if column > 0 {
+			pos = token.Position{Line: 1, Column: column}
+		}
+
+		return &StringLiteral{Token: token.Token{Type: token.STRING, Literal: value, Pos: pos}, Value: value}
+	}
+
+	value := func(n int64) *IntegerLiteral {
+		return &IntegerLiteral{Token: token.Token{Type: token.INT}, Value: n}
+	}
+
+	tests := []struct {
+		hash     *HashLiteral
+		expected []string
+	}{
+		{
+			// {"c": 1, "a": 2, "d": 3, "b": 4}
+			&HashLiteral{Pairs: map[Expression]Expression{
+				key("c", 2): value(1), key("a", 10): value(2), key("d", 18): value(3), key("b", 26): value(4),
+			}},
+			[]string{"c", "a", "d", "b"},
+		},
+		{
+			// Keys without positions, such as those created by a macro.
+			&HashLiteral{Pairs: map[Expression]Expression{
+				key("c", 0): value(1), key("a", 0): value(2), key("d", 0): value(3), key("b", 0): value(4),
+			}},
+			[]string{"a", "b", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		for run := 0; run < 10; run++ {
+			tree := testRoundTrip(t, tt.hash)
+
+			pairs, ok := tree["pairs"].([]interface{})
+			if !ok || len(pairs) != len(tt.expected) {
+				t.Fatalf("pairs wrong. got=%v", tree["pairs"])
+			}
+
+			for i, pair := range pairs {
+				key := pair.(map[string]interface{})["key"].(map[string]interface{})
+				if key["value"] != tt.expected[i] {
+					t.Fatalf("pair %d has the wrong key. got=%v, want=%q", i, key["value"], tt.expected[i])
+				}
+			}
+		}
+	}
+}
+
+func testRoundTrip(t *testing.T, node Node) map[string]interface{} {
+	data, err := MarshalJSON(node)
+	if err != nil {
+		t.Fatalf("MarshalJSON returned error: %s", err)
+	}
+
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("could not unmarshal %s: %s", data, err)
+	}
+
+	return tree
+}
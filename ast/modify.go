@@ -0,0 +1,132 @@
+package ast
+
+// ModifierFunc is called by Modify on every node in a tree, and returns the node that should replace it.
+type ModifierFunc func(Node) Node
+
+// Modify traverses the tree rooted at node, replacing every node with the result of calling modifier on it. Children are
+// modified before their parents, and the modified tree is returned.
+func Modify(node Node, modifier ModifierFunc) Node {
+	switch node := node.(type) {
+	case *Program:
+		for i, statement := range node.Statements {
+			node.Statements[i], _ = Modify(statement, modifier).(Statement)
+		}
+	case *ExpressionStatement:
+		node.Expression, _ = Modify(node.Expression, modifier).(Expression)
+	case *BlockStatement:
+		for i, statement := range node.Statements {
+			node.Statements[i], _ = Modify(statement, modifier).(Statement)
+		}
+	case *ReturnStatement:
+		node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
+	case *LetStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *ForStatement:
+		if node.Init != nil {
+			node.Init, _ = Modify(node.Init, modifier).(Statement)
+		}
+
+		if node.Condition != nil {
+			node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		}
+
+		if node.Post != nil {
+			node.Post, _ = Modify(node.Post, modifier).(Expression)
+		}
+
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *DoWhileStatement:
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+	case *ForInStatement:
+		node.Iterable, _ = Modify(node.Iterable, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *PrefixExpression:
+		node.Right, _ = Modify(node.Right, modifier).(Expression)
+	case *InfixExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		node.Right, _ = Modify(node.Right, modifier).(Expression)
+	case *AssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(Expression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *CompoundAssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(Expression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *PostfixExpression:
+		node.Target, _ = Modify(node.Target, modifier).(*Identifier)
+	case *IndexExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		node.Index, _ = Modify(node.Index, modifier).(Expression)
+	case *SliceExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+
+		if node.Low != nil {
+			node.Low, _ = Modify(node.Low, modifier).(Expression)
+		}
+
+		if node.High != nil {
+			node.High, _ = Modify(node.High, modifier).(Expression)
+		}
+	case *IfExpression:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Consequence, _ = Modify(node.Consequence, modifier).(*BlockStatement)
+
+		if node.Alternative != nil {
+			node.Alternative, _ = Modify(node.Alternative, modifier).(*BlockStatement)
+		}
+	case *TernaryExpression:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Consequence, _ = Modify(node.Consequence, modifier).(Expression)
+		node.Alternative, _ = Modify(node.Alternative, modifier).(Expression)
+	case *SwitchExpression:
+		node.Subject, _ = Modify(node.Subject, modifier).(Expression)
+
+		for i, c := range node.Cases {
+			node.Cases[i], _ = Modify(c, modifier).(*SwitchCase)
+		}
+
+		if node.Default != nil {
+			node.Default, _ = Modify(node.Default, modifier).(*BlockStatement)
+		}
+	case *SwitchCase:
+		for i, value := range node.Values {
+			node.Values[i], _ = Modify(value, modifier).(Expression)
+		}
+
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *TryExpression:
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+		node.Catch, _ = Modify(node.Catch, modifier).(*BlockStatement)
+	case *FunctionLiteral:
+		for i := range node.Parameters {
+			node.Parameters[i], _ = Modify(node.Parameters[i], modifier).(*Identifier)
+		}
+
+		for i, d := range node.Defaults {
+			if d != nil {
+				node.Defaults[i], _ = Modify(d, modifier).(Expression)
+			}
+		}
+
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *CallExpression:
+		for i, arg := range node.Arguments {
+			node.Arguments[i], _ = Modify(arg, modifier).(Expression)
+		}
+	case *ArrayLiteral:
+		for i, element := range node.Elements {
+			node.Elements[i], _ = Modify(element, modifier).(Expression)
+		}
+	case *HashLiteral:
+		pairs := make(map[Expression]Expression)
+		for key, value := range node.Pairs {
+			newKey, _ := Modify(key, modifier).(Expression)
+			newValue, _ := Modify(value, modifier).(Expression)
+			pairs[newKey] = newValue
+		}
+
+		node.Pairs = pairs
+	}
+
+	return modifier(node)
+}
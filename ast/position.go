@@ -0,0 +1,311 @@
+package ast
+
+import (
+	"reflect"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+// endOf returns the end of the node, or fallback if the node is missing, such as when the parser gave up on it.
+func endOf(node Node, fallback token.Position) token.Position {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return fallback
+	}
+
+	return node.End()
+}
+
+// posOf returns the position of the node, or fallback if the node is missing.
+func posOf(node Node, fallback token.Position) token.Position {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return fallback
+	}
+
+	return node.Pos()
+}
+
+// after reports whether position a comes after position b in the input.
+func after(a, b token.Position) bool {
+	return a.Line > b.Line || (a.Line == b.Line && a.Column > b.Column)
+}
+
+// Pos returns the position of the first statement in the program.
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) == 0 {
+		return token.Position{}
+	}
+
+	return p.Statements[0].Pos()
+}
+
+// End returns the end of the last statement in the program.
+func (p *Program) End() token.Position {
+	if len(p.Statements) == 0 {
+		return token.Position{}
+	}
+
+	return p.Statements[len(p.Statements)-1].End()
+}
+
+// Pos returns the position of the 'let' or 'const' token.
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Pos }
+
+// End returns the end of the value being bound, or of the name or pattern if the value is missing.
+func (ls *LetStatement) End() token.Position {
+	if ls.Pattern != nil {
+		return endOf(ls.Value, ls.Pattern.End())
+	}
+
+	return endOf(ls.Value, endOf(ls.Name, ls.Token.End))
+}
+
+// Pos returns the position of the identifier.
+func (i *Identifier) Pos() token.Position { return i.Token.Pos }
+
+// End returns the position just after the identifier.
+func (i *Identifier) End() token.Position { return i.Token.End }
+
+// Pos returns the position of the '[' token.
+func (ap *ArrayPattern) Pos() token.Position { return ap.Token.Pos }
+
+// End returns the end of the last name in the pattern, since the ']' token isn't stored.
+func (ap *ArrayPattern) End() token.Position {
+	if len(ap.Elements) == 0 {
+		return ap.Token.End
+	}
+
+	return endOf(ap.Elements[len(ap.Elements)-1], ap.Token.End)
+}
+
+// Pos returns the position of the 'return' token.
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Pos }
+
+// End returns the end of the returned value, or of the 'return' token if there isn't one.
+func (rs *ReturnStatement) End() token.Position { return endOf(rs.ReturnValue, rs.Token.End) }
+
+// Pos returns the position of the expression.
+func (es *ExpressionStatement) Pos() token.Position { return posOf(es.Expression, es.Token.Pos) }
+
+// End returns the end of the expression.
+func (es *ExpressionStatement) End() token.Position { return endOf(es.Expression, es.Token.End) }
+
+// Pos returns the position of the '{' token.
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Pos }
+
+// End returns the end of the last statement in the block, since the '}' token isn't stored.
+func (bs *BlockStatement) End() token.Position {
+	if len(bs.Statements) == 0 {
+		return bs.Token.End
+	}
+
+	return endOf(bs.Statements[len(bs.Statements)-1], bs.Token.End)
+}
+
+// Pos returns the position of the 'for' token.
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Pos }
+
+// End returns the end of the loop body.
+func (fs *ForStatement) End() token.Position { return endOf(fs.Body, fs.Token.End) }
+
+// Pos returns the position of the 'do' token.
+func (dw *DoWhileStatement) Pos() token.Position { return dw.Token.Pos }
+
+// End returns the end of the loop condition.
+func (dw *DoWhileStatement) End() token.Position {
+	return endOf(dw.Condition, endOf(dw.Body, dw.Token.End))
+}
+
+// Pos returns the position of the 'for' token.
+func (fi *ForInStatement) Pos() token.Position { return fi.Token.Pos }
+
+// End returns the end of the loop body.
+func (fi *ForInStatement) End() token.Position { return endOf(fi.Body, fi.Token.End) }
+
+// Pos returns the position of the 'break' token.
+func (bs *BreakStatement) Pos() token.Position { return bs.Token.Pos }
+
+// End returns the position just after the 'break' token.
+func (bs *BreakStatement) End() token.Position { return bs.Token.End }
+
+// Pos returns the position of the 'continue' token.
+func (cs *ContinueStatement) Pos() token.Position { return cs.Token.Pos }
+
+// End returns the position just after the 'continue' token.
+func (cs *ContinueStatement) End() token.Position { return cs.Token.End }
+
+// Pos returns the position of the integer, including its minus sign if it has one.
+func (il *IntegerLiteral) Pos() token.Position { return il.Token.Pos }
+
+// End returns the position just after the integer.
+func (il *IntegerLiteral) End() token.Position { return il.Token.End }
+
+// Pos returns the position of the 'true' or 'false' token.
+func (b *Boolean) Pos() token.Position { return b.Token.Pos }
+
+// End returns the position just after the 'true' or 'false' token.
+func (b *Boolean) End() token.Position { return b.Token.End }
+
+// Pos returns the position of the operator.
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Pos }
+
+// End returns the end of the operand.
+func (pe *PrefixExpression) End() token.Position { return endOf(pe.Right, pe.Token.End) }
+
+// Pos returns the position of the left operand.
+func (ie *InfixExpression) Pos() token.Position { return posOf(ie.Left, ie.Token.Pos) }
+
+// End returns the end of the right operand.
+func (ie *InfixExpression) End() token.Position { return endOf(ie.Right, ie.Token.End) }
+
+// Pos returns the position of the target being assigned to.
+func (ae *AssignExpression) Pos() token.Position { return posOf(ae.Target, ae.Token.Pos) }
+
+// End returns the end of the value being assigned.
+func (ae *AssignExpression) End() token.Position { return endOf(ae.Value, ae.Token.End) }
+
+// Pos returns the position of the target being assigned to.
+func (ce *CompoundAssignExpression) Pos() token.Position { return posOf(ce.Target, ce.Token.Pos) }
+
+// End returns the end of the value on the right of the operator.
+func (ce *CompoundAssignExpression) End() token.Position { return endOf(ce.Value, ce.Token.End) }
+
+// Pos returns the position of the identifier being incremented or decremented.
+func (pe *PostfixExpression) Pos() token.Position { return posOf(pe.Target, pe.Token.Pos) }
+
+// End returns the position just after the '++' or '--' token.
+func (pe *PostfixExpression) End() token.Position { return pe.Token.End }
+
+// Pos returns the position of the 'if' token.
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Pos }
+
+// End returns the end of the else block, or of the consequence if there isn't one.
+func (ie *IfExpression) End() token.Position {
+	return endOf(ie.Alternative, endOf(ie.Consequence, endOf(ie.Condition, ie.Token.End)))
+}
+
+// Pos returns the position of the condition.
+func (te *TernaryExpression) Pos() token.Position { return posOf(te.Condition, te.Token.Pos) }
+
+// End returns the end of the alternative.
+func (te *TernaryExpression) End() token.Position {
+	return endOf(te.Alternative, endOf(te.Consequence, te.Token.End))
+}
+
+// Pos returns the position of the 'switch' token.
+func (se *SwitchExpression) Pos() token.Position { return se.Token.Pos }
+
+// End returns the end of whichever case comes last in the input, since the default case can appear anywhere.
+func (se *SwitchExpression) End() token.Position {
+	end := endOf(se.Subject, se.Token.End)
+
+	for _, c := range se.Cases {
+		if e := endOf(c, end); after(e, end) {
+			end = e
+		}
+	}
+
+	if e := endOf(se.Default, end); after(e, end) {
+		end = e
+	}
+
+	return end
+}
+
+// Pos returns the position of the 'case' or 'default' token.
+func (sc *SwitchCase) Pos() token.Position { return sc.Token.Pos }
+
+// End returns the end of the body of the case.
+func (sc *SwitchCase) End() token.Position {
+	end := sc.Token.End
+	if len(sc.Values) != 0 {
+		end = endOf(sc.Values[len(sc.Values)-1], end)
+	}
+
+	return endOf(sc.Body, end)
+}
+
+// Pos returns the position of the 'try' token.
+func (te *TryExpression) Pos() token.Position { return te.Token.Pos }
+
+// End returns the end of the catch block.
+func (te *TryExpression) End() token.Position {
+	return endOf(te.Catch, endOf(te.Parameter, endOf(te.Body, te.Token.End)))
+}
+
+// Pos returns the position of the 'fn' token.
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Pos }
+
+// End returns the end of the function body.
+func (fl *FunctionLiteral) End() token.Position { return endOf(fl.Body, fl.Token.End) }
+
+// Pos returns the position of the 'macro' token.
+func (ml *MacroLiteral) Pos() token.Position { return ml.Token.Pos }
+
+// End returns the end of the macro body.
+func (ml *MacroLiteral) End() token.Position { return endOf(ml.Body, ml.Token.End) }
+
+// Pos returns the position of the function being called.
+func (ce *CallExpression) Pos() token.Position { return posOf(ce.Function, ce.Token.Pos) }
+
+// End returns the end of the last argument, since the ')' token isn't stored.
+func (ce *CallExpression) End() token.Position {
+	if len(ce.Arguments) == 0 {
+		return endOf(ce.Function, ce.Token.End)
+	}
+
+	return endOf(ce.Arguments[len(ce.Arguments)-1], ce.Token.End)
+}
+
+// Pos returns the position of the 'null' token.
+func (nl *NullLiteral) Pos() token.Position { return nl.Token.Pos }
+
+// End returns the position just after the 'null' token.
+func (nl *NullLiteral) End() token.Position { return nl.Token.End }
+
+// Pos returns the position of the opening quote.
+func (sl *StringLiteral) Pos() token.Position { return sl.Token.Pos }
+
+// End returns the position just after the closing quote.
+func (sl *StringLiteral) End() token.Position { return sl.Token.End }
+
+// Pos returns the position of the '[' token.
+func (al *ArrayLiteral) Pos() token.Position { return al.Token.Pos }
+
+// End returns the end of the last element, since the ']' token isn't stored.
+func (al *ArrayLiteral) End() token.Position {
+	if len(al.Elements) == 0 {
+		return al.Token.End
+	}
+
+	return endOf(al.Elements[len(al.Elements)-1], al.Token.End)
+}
+
+// Pos returns the position of the expression being indexed.
+func (ie *IndexExpression) Pos() token.Position { return posOf(ie.Left, ie.Token.Pos) }
+
+// End returns the end of the index, since the ']' token isn't stored.
+func (ie *IndexExpression) End() token.Position { return endOf(ie.Index, ie.Token.End) }
+
+// Pos returns the position of the expression being sliced.
+func (se *SliceExpression) Pos() token.Position { return posOf(se.Left, se.Token.Pos) }
+
+// End returns the end of the last bound of the slice, since the ']' token isn't stored.
+func (se *SliceExpression) End() token.Position {
+	return endOf(se.High, endOf(se.Low, se.Token.End))
+}
+
+// Pos returns the position of the '{' token.
+func (hl *HashLiteral) Pos() token.Position { return hl.Token.Pos }
+
+// End returns the end of whichever pair comes last in the input, since the pairs are stored in a map.
+func (hl *HashLiteral) End() token.Position {
+	end := hl.Token.End
+
+	for key, value := range hl.Pairs {
+		if e := endOf(value, endOf(key, end)); after(e, end) {
+			end = e
+		}
+	}
+
+	return end
+}
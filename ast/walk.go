@@ -0,0 +1,129 @@
+package ast
+
+import "reflect"
+
+// Walk traverses the tree rooted at node in pre-order, calling fn for every node it visits. The children of a node are
+// only visited if fn returns true for that node. Nil nodes, such as a missing else block, are skipped.
+func Walk(node Node, fn func(Node) bool) {
+	// Nodes are always pointers, and the parser can leave typed nil pointers in the tree.
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return
+	}
+
+	if !fn(node) {
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, s := range node.Statements {
+			Walk(s, fn)
+		}
+	case *LetStatement:
+		Walk(node.Name, fn)
+		Walk(node.Pattern, fn)
+		Walk(node.Value, fn)
+	case *ArrayPattern:
+		for _, e := range node.Elements {
+			Walk(e, fn)
+		}
+	case *ReturnStatement:
+		Walk(node.ReturnValue, fn)
+	case *ExpressionStatement:
+		Walk(node.Expression, fn)
+	case *BlockStatement:
+		for _, s := range node.Statements {
+			Walk(s, fn)
+		}
+	case *ForStatement:
+		Walk(node.Init, fn)
+		Walk(node.Condition, fn)
+		Walk(node.Post, fn)
+		Walk(node.Body, fn)
+	case *DoWhileStatement:
+		Walk(node.Body, fn)
+		Walk(node.Condition, fn)
+	case *ForInStatement:
+		Walk(node.Key, fn)
+		Walk(node.Value, fn)
+		Walk(node.Iterable, fn)
+		Walk(node.Body, fn)
+	case *PrefixExpression:
+		Walk(node.Right, fn)
+	case *InfixExpression:
+		Walk(node.Left, fn)
+		Walk(node.Right, fn)
+	case *AssignExpression:
+		Walk(node.Target, fn)
+		Walk(node.Value, fn)
+	case *CompoundAssignExpression:
+		Walk(node.Target, fn)
+		Walk(node.Value, fn)
+	case *PostfixExpression:
+		Walk(node.Target, fn)
+	case *IfExpression:
+		Walk(node.Condition, fn)
+		Walk(node.Consequence, fn)
+		Walk(node.Alternative, fn)
+	case *TernaryExpression:
+		Walk(node.Condition, fn)
+		Walk(node.Consequence, fn)
+		Walk(node.Alternative, fn)
+	case *SwitchExpression:
+		Walk(node.Subject, fn)
+
+		for _, c := range node.Cases {
+			Walk(c, fn)
+		}
+
+		Walk(node.Default, fn)
+	case *SwitchCase:
+		for _, v := range node.Values {
+			Walk(v, fn)
+		}
+
+		Walk(node.Body, fn)
+	case *TryExpression:
+		Walk(node.Body, fn)
+		Walk(node.Parameter, fn)
+		Walk(node.Catch, fn)
+	case *FunctionLiteral:
+		for _, p := range node.Parameters {
+			Walk(p, fn)
+		}
+
+		for _, d := range node.Defaults {
+			Walk(d, fn)
+		}
+
+		Walk(node.Body, fn)
+	case *MacroLiteral:
+		for _, p := range node.Parameters {
+			Walk(p, fn)
+		}
+
+		Walk(node.Body, fn)
+	case *CallExpression:
+		Walk(node.Function, fn)
+
+		for _, a := range node.Arguments {
+			Walk(a, fn)
+		}
+	case *ArrayLiteral:
+		for _, e := range node.Elements {
+			Walk(e, fn)
+		}
+	case *IndexExpression:
+		Walk(node.Left, fn)
+		Walk(node.Index, fn)
+	case *SliceExpression:
+		Walk(node.Left, fn)
+		Walk(node.Low, fn)
+		Walk(node.High, fn)
+	case *HashLiteral:
+		for key, value := range node.Pairs {
+			Walk(key, fn)
+			Walk(value, fn)
+		}
+	}
+}
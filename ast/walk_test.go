@@ -0,0 +1,100 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ollybritton/monkey/token"
+)
+
+// testWalkProgram builds the AST for "let add = fn(x, y) { x + y }; add(1, z);"
+func testWalkProgram() *Program {
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+
+	return &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  ident("add"),
+				Value: &FunctionLiteral{
+					Token:      token.Token{Type: token.FUNCTION, Literal: "fn"},
+					Parameters: []*Identifier{ident("x"), ident("y")},
+					Body: &BlockStatement{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Statements: []Statement{
+							&ExpressionStatement{
+								Token: token.Token{Type: token.IDENT, Literal: "x"},
+								Expression: &InfixExpression{
+									Token:    token.Token{Type: token.PLUS, Literal: "+"},
+									Left:     ident("x"),
+									Operator: "+",
+									Right:    ident("y"),
+								},
+							},
+						},
+					},
+				},
+			},
+			&ExpressionStatement{
+				Token: token.Token{Type: token.IDENT, Literal: "add"},
+				Expression: &CallExpression{
+					Token:    token.Token{Type: token.LPAREN, Literal: "("},
+					Function: ident("add"),
+					Arguments: []Expression{
+						&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+						ident("z"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWalkCountsNodes(t *testing.T) {
+	count := 0
+	Walk(testWalkProgram(), func(n Node) bool {
+		count++
+		return true
+	})
+
+	// Program, LetStatement, add, FunctionLiteral, x, y, BlockStatement, ExpressionStatement, InfixExpression, x, y,
+	// ExpressionStatement, CallExpression, add, 1, z
+	if count != 16 {
+		t.Errorf("wrong number of nodes visited. got=%d, want=16", count)
+	}
+}
+
+func TestWalkCollectsIdentifiers(t *testing.T) {
+	names := []string{}
+	Walk(testWalkProgram(), func(n Node) bool {
+		if ident, ok := n.(*Identifier); ok {
+			names = append(names, ident.Value)
+		}
+
+		return true
+	})
+
+	expected := []string{"add", "x", "y", "x", "y", "add", "z"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("wrong identifiers collected. got=%v, want=%v", names, expected)
+	}
+}
+
+func TestWalkSkipsChildren(t *testing.T) {
+	names := []string{}
+	Walk(testWalkProgram(), func(n Node) bool {
+		if ident, ok := n.(*Identifier); ok {
+			names = append(names, ident.Value)
+		}
+
+		_, isFunction := n.(*FunctionLiteral)
+		return !isFunction
+	})
+
+	expected := []string{"add", "add", "z"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("wrong identifiers collected. got=%v, want=%v", names, expected)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+// ANSI escape codes used to color REPL output.
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorGray    = "\x1b[90m"
+)
+
+// colorFor returns the ANSI escape code used to color objects of the given type, or "" if they aren't colored.
+func colorFor(t object.ObjectType) string {
+	switch t {
+	case object.INTEGER_OBJ:
+		return colorBlue
+	case object.STRING_OBJ:
+		return colorGreen
+	case object.BOOLEAN_OBJ:
+		return colorYellow
+	case object.NULL_OBJ:
+		return colorGray
+	case object.ERROR_OBJ:
+		return colorRed
+	case object.FUNCTION_OBJ, object.BUILTIN_OBJ:
+		return colorMagenta
+	default:
+		return ""
+	}
+}
+
+// colorize returns the string representation of an object, wrapped in the color for its type.
+func colorize(obj object.Object) string {
+	color := colorFor(obj.Type())
+	if color == "" {
+		return obj.Inspect()
+	}
+
+	return color + obj.Inspect() + colorReset
+}
+
+// isTerminal reports whether the file is a terminal rather than, for example, a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+func TestColorFor(t *testing.T) {
+	tests := []struct {
+		objectType object.ObjectType
+		expected   string
+	}{
+		{object.INTEGER_OBJ, colorBlue},
+		{object.STRING_OBJ, colorGreen},
+		{object.BOOLEAN_OBJ, colorYellow},
+		{object.NULL_OBJ, colorGray},
+		{object.ERROR_OBJ, colorRed},
+		{object.FUNCTION_OBJ, colorMagenta},
+		{object.BUILTIN_OBJ, colorMagenta},
+		{object.ARRAY_OBJ, ""},
+		{object.HASH_OBJ, ""},
+	}
+
+	for _, tt := range tests {
+		if got := colorFor(tt.objectType); got != tt.expected {
+			t.Errorf("colorFor(%s) wrong. got=%q, want=%q", tt.objectType, got, tt.expected)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	if got := colorize(&object.Integer{Value: 5}); got != "\x1b[34m5\x1b[0m" {
+		t.Errorf("colorize(5) wrong. got=%q", got)
+	}
+
+	if got := colorize(&object.Array{Elements: []object.Object{}}); got != "[]" {
+		t.Errorf("colorize([]) wrong. got=%q", got)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "output")
+	if err != nil {
+		t.Fatalf("could not create temp file: %s", err)
+	}
+	defer f.Close()
+
+	if isTerminal(f) {
+		t.Errorf("a regular file should not be a terminal")
+	}
+}
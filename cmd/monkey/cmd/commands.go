@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+// runReplCommand handles the special colon-commands in the eval REPL, such as ":reset". If the line is not a command,
+// handled is false and the line should be evaluated as normal. Otherwise, env and macroEnv are the environments to use
+// for values and macros from now on and quit is true if the REPL should stop. last is the most recently evaluated
+// object, or nil if nothing has been evaluated.
+func runReplCommand(line string, current, currentMacros *object.Environment, last object.Object, out io.Writer) (env, macroEnv *object.Environment, quit bool, handled bool) {
+	command := strings.TrimSpace(line)
+	if !strings.HasPrefix(command, ":") {
+		return current, currentMacros, false, false
+	}
+
+	switch command {
+	case ":reset":
+		fmt.Fprintln(out, "environment reset")
+		return object.NewEnvironment(), object.NewEnvironment(), false, true
+	case ":env":
+		for _, name := range current.Names() {
+			value, _ := current.Get(name)
+			fmt.Fprintf(out, "%s = %s\n", name, value.Inspect())
+		}
+
+		return current, currentMacros, false, true
+	case ":type":
+		if last == nil {
+			fmt.Fprintln(out, "nothing has been evaluated yet")
+		} else {
+			fmt.Fprintln(out, last.Type())
+		}
+
+		return current, currentMacros, false, true
+	case ":quit":
+		return current, currentMacros, true, true
+	default:
+		fmt.Fprintf(out, "unknown command %s, expected one of :reset, :env, :type or :quit\n", command)
+		return current, currentMacros, false, true
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+func TestRunReplCommand(t *testing.T) {
+	tests := []struct {
+		line        string
+		resets      bool
+		quit        bool
+		handled     bool
+		expectedOut string
+	}{
+		{"let a = 1;", false, false, false, ""},
+		{":reset", true, false, true, "environment reset\n"},
+		{"  :reset  ", true, false, true, "environment reset\n"},
+		{":env", false, false, true, "a = 1\nb = hello\n"},
+		{":quit", false, true, true, ""},
+		{":nope", false, false, true, "unknown command :nope, expected one of :reset, :env, :type or :quit\n"},
+	}
+
+	for _, tt := range tests {
+		current := object.NewEnvironment()
+		current.Set("b", &object.String{Value: "hello"})
+		current.Set("a", &object.Integer{Value: 1})
+		currentMacros := object.NewEnvironment()
+		currentMacros.Set("m", &object.Macro{})
+
+		var out bytes.Buffer
+		env, macroEnv, quit, handled := runReplCommand(tt.line, current, currentMacros, nil, &out)
+
+		if handled != tt.handled {
+			t.Errorf("%q: handled wrong. got=%t, want=%t", tt.line, handled, tt.handled)
+		}
+
+		if quit != tt.quit {
+			t.Errorf("%q: quit wrong. got=%t, want=%t", tt.line, quit, tt.quit)
+		}
+
+		if (env != current) != tt.resets {
+			t.Errorf("%q: environment reset wrong. want reset=%t", tt.line, tt.resets)
+		}
+
+		if (macroEnv != currentMacros) != tt.resets {
+			t.Errorf("%q: macro environment reset wrong. want reset=%t", tt.line, tt.resets)
+		}
+
+		if tt.resets && (len(env.Names()) != 0 || len(macroEnv.Names()) != 0) {
+			t.Errorf("%q: new environments are not empty", tt.line)
+		}
+
+		if out.String() != tt.expectedOut {
+			t.Errorf("%q: wrong output. got=%q, want=%q", tt.line, out.String(), tt.expectedOut)
+		}
+	}
+}
+
+func TestRunReplCommandType(t *testing.T) {
+	tests := []struct {
+		last        object.Object
+		expectedOut string
+	}{
+		{nil, "nothing has been evaluated yet\n"},
+		{&object.Integer{Value: 5}, "INTEGER\n"},
+		{&object.String{Value: "hello"}, "STRING\n"},
+		{&object.Error{Message: "oops"}, "ERROR\n"},
+	}
+
+	for _, tt := range tests {
+		current := object.NewEnvironment()
+		currentMacros := object.NewEnvironment()
+
+		var out bytes.Buffer
+		env, macroEnv, quit, handled := runReplCommand(":type", current, currentMacros, tt.last, &out)
+
+		if !handled || quit || env != current || macroEnv != currentMacros {
+			t.Errorf(":type: wrong result. handled=%t, quit=%t, same env=%t", handled, quit, env == current)
+		}
+
+		if out.String() != tt.expectedOut {
+			t.Errorf(":type: wrong output. got=%q, want=%q", out.String(), tt.expectedOut)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ollybritton/monkey/ast"
+)
+
+// countNodes tallies how many of each type of node are in the program, keyed by the name of the node's type such as
+// "LetStatement". The program itself is not counted.
+func countNodes(program *ast.Program) map[string]int {
+	counts := make(map[string]int)
+
+	ast.Walk(program, func(node ast.Node) bool {
+		if node != program {
+			counts[strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")]++
+		}
+
+		return true
+	})
+
+	return counts
+}
+
+// writeNodeCounts writes the number of top-level statements in the program, followed by the number of each type of node
+// in alphabetical order.
+func writeNodeCounts(out io.Writer, program *ast.Program) {
+	fmt.Fprintf(out, "statements: %d\n", len(program.Statements))
+
+	counts := countNodes(program)
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "%s: %d\n", name, counts[name])
+	}
+}
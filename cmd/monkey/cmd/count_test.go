@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/parser"
+)
+
+func TestCountNodes(t *testing.T) {
+	program := parser.New(lexer.New("let a = 1 + 2; a * 3;")).ParseProgram()
+
+	expected := map[string]int{
+		"LetStatement":        1,
+		"ExpressionStatement": 1,
+		"Identifier":          2,
+		"InfixExpression":     2,
+		"IntegerLiteral":      3,
+	}
+
+	counts := countNodes(program)
+
+	if len(counts) != len(expected) {
+		t.Errorf("wrong number of node types. got=%v, want=%v", counts, expected)
+	}
+
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("wrong count for %s. got=%d, want=%d", name, counts[name], count)
+		}
+	}
+}
+
+func TestWriteNodeCounts(t *testing.T) {
+	program := parser.New(lexer.New("let a = 1; a;")).ParseProgram()
+
+	var out bytes.Buffer
+	writeNodeCounts(&out, program)
+
+	expected := "statements: 2\nExpressionStatement: 1\nIdentifier: 2\nIntegerLiteral: 1\nLetStatement: 1\n"
+	if out.String() != expected {
+		t.Errorf("wrong output. got=%q, want=%q", out.String(), expected)
+	}
+}
@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/ollybritton/monkey/object"
 
-	"github.com/chzyer/readline"
 	"github.com/ollybritton/monkey/evaluator"
 	"github.com/ollybritton/monkey/lexer"
 	"github.com/ollybritton/monkey/parser"
@@ -13,6 +13,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// evalColor is set by the --color flag, and makes the eval command color its results by type when writing to a terminal.
+var evalColor bool
+
+// evalLoad is set by the --load flag to the path of a file which is evaluated before the REPL starts.
+var evalLoad string
+
 // replCmd represents the repl command
 var replCmd = &cobra.Command{
 	Use:   "eval",
@@ -21,12 +27,22 @@ var replCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("monkey :: Evaluation\n\n")
 
-		rl, err := readline.New("==> ")
+		color := evalColor && isTerminal(os.Stdout)
+
+		rl, err := newReadline()
 		if err != nil {
 			panic(errors.Wrap(err, "error creating repl"))
 		}
 		defer rl.Close()
 		env := object.NewEnvironment()
+		macroEnv := object.NewEnvironment()
+
+		if evalLoad != "" {
+			loadFile(evalLoad, env, macroEnv, os.Stdout)
+		}
+
+		// last is the most recently evaluated object, used by the :type command.
+		var last object.Object
 
 		for {
 			line, err := rl.Readline()
@@ -34,22 +50,47 @@ var replCmd = &cobra.Command{
 				break
 			}
 
+			var quit, handled bool
+			if env, macroEnv, quit, handled = runReplCommand(line, env, macroEnv, last, os.Stdout); handled {
+				if quit {
+					break
+				}
+
+				fmt.Println("")
+				continue
+			}
+
 			l := lexer.New(line)
 			p := parser.New(l)
 			program := p.ParseProgram()
 
-			if len(p.Errors()) != 0 {
-				for _, e := range p.Errors() {
-					fmt.Println("\t", e)
-				}
+			if writeErrors(os.Stdout, l, p) {
+				fmt.Println("")
+			}
 
+			evaluator.DefineMacros(program, macroEnv)
+			expanded, err := evaluator.ExpandMacros(program, macroEnv)
+			if err != nil {
+				fmt.Println("\t", err)
 				fmt.Println("")
+				continue
 			}
 
-			evaluated := evaluator.Eval(program, env)
+			evaluated := evaluator.Eval(expanded, env)
+
+			if exit, ok := evaluated.(*object.Exit); ok {
+				rl.Close()
+				os.Exit(int(exit.Code))
+			}
 
 			if evaluated != nil {
-				fmt.Println(evaluated.Inspect())
+				last = evaluated
+
+				if color {
+					fmt.Println(colorize(evaluated))
+				} else {
+					fmt.Println(evaluated.Inspect())
+				}
 			}
 
 			fmt.Println("")
@@ -59,4 +100,7 @@ var replCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(replCmd)
+
+	replCmd.Flags().BoolVar(&evalColor, "color", false, "Color results by type when writing to a terminal")
+	replCmd.Flags().StringVar(&evalLoad, "load", "", "Evaluate a file before starting the REPL")
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/chzyer/readline"
+)
+
+// historyLimit is the maximum number of lines kept in the REPL history file.
+const historyLimit = 1000
+
+// historyPath returns the path of the REPL history file inside the given config directory, creating a "monkey"
+// directory inside it if it doesn't already exist.
+func historyPath(configDir string) (string, error) {
+	dir := filepath.Join(configDir, "monkey")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "history"), nil
+}
+
+// newReadline creates a new readline instance that is shared by all the REPL commands. History is persisted to a file
+// in the user's config directory, falling back to in-memory history if that directory can't be used.
+func newReadline() (*readline.Instance, error) {
+	config := &readline.Config{
+		Prompt:       "==> ",
+		HistoryLimit: historyLimit,
+	}
+
+	if configDir, err := os.UserConfigDir(); err == nil {
+		if path, err := historyPath(configDir); err == nil {
+			config.HistoryFile = path
+		}
+	}
+
+	return readline.NewEx(config)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryPath(t *testing.T) {
+	configDir := t.TempDir()
+
+	path, err := historyPath(configDir)
+	if err != nil {
+		t.Fatalf("historyPath returned error: %s", err)
+	}
+
+	expected := filepath.Join(configDir, "monkey", "history")
+	if path != expected {
+		t.Errorf("wrong history path. expected=%q, got=%q", expected, path)
+	}
+
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil || !info.IsDir() {
+		t.Errorf("history directory was not created")
+	}
+}
+
+func TestHistoryPathUnusableDirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte{}, 0644); err != nil {
+		t.Fatalf("could not create file: %s", err)
+	}
+
+	if _, err := historyPath(file); err == nil {
+		t.Errorf("expected an error when the config directory is a file")
+	}
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/chzyer/readline"
 	"github.com/ollybritton/monkey/lexer"
 	"github.com/ollybritton/monkey/token"
 	"github.com/pkg/errors"
@@ -18,7 +17,7 @@ var lexCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("monkey :: Lexical Analysis\n\n")
 
-		rl, err := readline.New("==> ")
+		rl, err := newReadline()
 		if err != nil {
 			panic(errors.Wrap(err, "error creating repl"))
 		}
@@ -31,8 +30,15 @@ var lexCmd = &cobra.Command{
 			}
 
 			l := lexer.New(line)
-			for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-				fmt.Printf("%+v\n", tok)
+
+			for _, tok := range l.Tokens() {
+				if tok.Type != token.EOF {
+					fmt.Printf("%+v\n", tok)
+				}
+			}
+
+			for _, e := range l.Errors() {
+				fmt.Println("\t" + e.Error())
 			}
 
 			fmt.Println("")
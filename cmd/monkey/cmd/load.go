@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ollybritton/monkey/evaluator"
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/parser"
+)
+
+// loadFile evaluates the program in the file at path into env, so that its definitions can be used from the REPL. Any
+// errors reading, parsing or evaluating the file are written to out rather than returned, since they shouldn't stop the
+// REPL from starting. A file with parse errors is not evaluated at all.
+func loadFile(path string, env, macroEnv *object.Environment, out io.Writer) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(out, "\t", err)
+		fmt.Fprintln(out, "")
+		return
+	}
+
+	l := lexer.New(string(source))
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if writeErrors(out, l, p) {
+		fmt.Fprintln(out, "")
+		return
+	}
+
+	evaluator.DefineMacros(program, macroEnv)
+	expanded, err := evaluator.ExpandMacros(program, macroEnv)
+	if err != nil {
+		fmt.Fprintln(out, "\t", err)
+		fmt.Fprintln(out, "")
+		return
+	}
+
+	if evaluated, ok := evaluator.Eval(expanded, env).(*object.Error); ok {
+		fmt.Fprintln(out, "\t", evaluated.Inspect())
+		fmt.Fprintln(out, "")
+	}
+}
+
+// writeErrors writes the errors found by the lexer and the parser to out, returning true if there were any. Lexer
+// errors are written first, since they are usually the cause of the parser errors which follow them.
+func writeErrors(out io.Writer, l *lexer.Lexer, p *parser.Parser) bool {
+	for _, e := range l.Errors() {
+		fmt.Fprintln(out, "\t", e)
+	}
+
+	for _, e := range p.Errors() {
+		fmt.Fprintln(out, "\t", e)
+	}
+
+	return len(l.Errors()) != 0 || len(p.Errors()) != 0
+}
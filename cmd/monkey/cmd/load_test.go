@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ollybritton/monkey/evaluator"
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/parser"
+)
+
+// writeSource writes source to a file in a temporary directory and returns its path.
+func writeSource(t *testing.T, source string) string {
+	path := filepath.Join(t.TempDir(), "source.mk")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatalf("could not write source file: %s", err)
+	}
+
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeSource(t, `
+let double = fn(x) { x * 2 };
+let unless = macro(cond, body) { quote(if (!(unquote(cond))) { unquote(body) }) };
+let base = 10;
+`)
+
+	env := object.NewEnvironment()
+	macroEnv := object.NewEnvironment()
+
+	var out bytes.Buffer
+	loadFile(path, env, macroEnv, &out)
+
+	if out.Len() != 0 {
+		t.Fatalf("loading produced output: %q", out.String())
+	}
+
+	program := parser.New(lexer.New("unless(false, double(base) + 1)")).ParseProgram()
+	evaluator.DefineMacros(program, macroEnv)
+
+	expanded, err := evaluator.ExpandMacros(program, macroEnv)
+	if err != nil {
+		t.Fatalf("could not expand macros: %s", err)
+	}
+
+	result, ok := evaluator.Eval(expanded, env).(*object.Integer)
+	if !ok || result.Value != 21 {
+		t.Errorf("function from loaded file not callable. got=%+v", result)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"let a = ;", "no prefix parse function for ;"},
+		{"let a = @;", "1:9: unexpected character '@'"},
+		{"let a = 1; missing;", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+
+		var out bytes.Buffer
+		loadFile(writeSource(t, tt.source), env, object.NewEnvironment(), &out)
+
+		if !strings.Contains(out.String(), tt.expected) {
+			t.Errorf("%q: expected output to contain %q, got %q", tt.source, tt.expected, out.String())
+		}
+	}
+
+	// Definitions before a runtime error are still kept.
+	env := object.NewEnvironment()
+	loadFile(writeSource(t, "let a = 1; missing;"), env, object.NewEnvironment(), &bytes.Buffer{})
+
+	if _, ok := env.Get("a"); !ok {
+		t.Errorf("definition before runtime error was not kept")
+	}
+
+	var out bytes.Buffer
+	loadFile(filepath.Join(t.TempDir(), "missing.mk"), object.NewEnvironment(), object.NewEnvironment(), &out)
+
+	if out.Len() == 0 {
+		t.Errorf("expected an error for a missing file")
+	}
+}
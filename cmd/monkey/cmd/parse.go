@@ -2,14 +2,21 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
-	"github.com/chzyer/readline"
+	"github.com/ollybritton/monkey/ast"
 	"github.com/ollybritton/monkey/lexer"
 	"github.com/ollybritton/monkey/parser"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// parseJSON is set by the --json flag, and makes the parse command output the AST as JSON.
+var parseJSON bool
+
+// parseCount is set by the --count flag, and makes the parse command print how many of each type of node were parsed.
+var parseCount bool
+
 // parseCmd represents the parse command
 var parseCmd = &cobra.Command{
 	Use:   "parse",
@@ -23,7 +30,7 @@ to quickly create a Cobra application.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("monkey :: Parser\n\n")
 
-		rl, err := readline.New("==> ")
+		rl, err := newReadline()
 		if err != nil {
 			panic(errors.Wrap(err, "error creating repl"))
 		}
@@ -39,13 +46,29 @@ to quickly create a Cobra application.`,
 			p := parser.New(l)
 
 			program := p.ParseProgram()
-			if len(p.Errors()) != 0 {
-				for _, msg := range p.Errors() {
-					fmt.Println("\t" + msg)
+			for _, e := range l.Errors() {
+				fmt.Println("\t" + e.Error())
+			}
+
+			for _, msg := range p.Errors() {
+				fmt.Println("\t" + msg)
+			}
+
+			if parseJSON {
+				out, err := ast.MarshalIndentJSON(program, "", "  ")
+				if err != nil {
+					fmt.Println("\t" + err.Error())
+				} else {
+					fmt.Println(string(out))
 				}
+			} else {
+				fmt.Println(program.String())
+			}
+
+			if parseCount {
+				writeNodeCounts(os.Stderr, program)
 			}
 
-			fmt.Println(program.String())
 			fmt.Println("")
 		}
 	},
@@ -63,4 +86,6 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// parseCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	parseCmd.Flags().BoolVar(&parseJSON, "json", false, "Output the AST as JSON")
+	parseCmd.Flags().BoolVar(&parseCount, "count", false, "Print the number of statements and of each type of node to stderr")
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Build information. These are set at build time using -ldflags, for example:
+//
+//	go build -ldflags "-X github.com/ollybritton/monkey/cmd/monkey/cmd.Version=1.0.0" ./cmd/monkey
+var (
+	Version   = "dev"
+	Commit    = "none"
+	BuildDate = "unknown"
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Display the version of the interpreter.",
+	Long:  `version displays the version, git commit and build date of the interpreter.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Fprintf(cmd.OutOrStdout(), "monkey %s (commit %s, built %s)\n", Version, Commit, BuildDate)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVersionCommand(t *testing.T) {
+	var out bytes.Buffer
+
+	versionCmd.SetOut(&out)
+	versionCmd.Run(versionCmd, []string{})
+
+	if !strings.Contains(out.String(), "dev") {
+		t.Errorf("version output does not contain default version. got=%q", out.String())
+	}
+}
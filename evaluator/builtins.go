@@ -1,11 +1,41 @@
 package evaluator
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ollybritton/monkey/object"
 )
 
+// output is where the `puts` and `print` builtins, and the prompt of the `input` builtin, are written to. It can be
+// changed with SetOutput.
+var output io.Writer = os.Stdout
+
+// SetOutput changes where the output of builtins such as `puts` is written to, which is os.Stdout by default.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// inputReader is where the `input` builtin reads lines from. It can be replaced to provide input from somewhere other
+// than stdin, such as in tests.
+var inputReader = bufio.NewReader(os.Stdin)
+
+// nowFunc is the time source used by the `clock` builtin. It can be replaced to make the clock deterministic in tests.
+var nowFunc = time.Now
+
+// random is the source used by the `rand` builtin. It can be reseeded with the `seed` builtin to produce a reproducible
+// sequence of numbers.
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
@@ -18,6 +48,10 @@ var builtins = map[string]*object.Builtin{
 				return &object.Integer{Value: int64(len(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
+			case *object.Integer, *object.Boolean:
+				return newError("argument to `len` has no length, got %s", args[0].Type())
 			default:
 				return newError("argument to `len` not supported, got %s", args[0].Type())
 			}
@@ -31,8 +65,16 @@ var builtins = map[string]*object.Builtin{
 
 			switch arg := args[0].(type) {
 			case *object.String:
+				if len(arg.Value) == 0 {
+					return NULL
+				}
+
 				return &object.String{Value: string(arg.Value[0])}
 			case *object.Array:
+				if len(arg.Elements) == 0 {
+					return NULL
+				}
+
 				return arg.Elements[0]
 			default:
 				return newError("argument to `first` not supported, got %s", args[0].Type())
@@ -80,14 +122,18 @@ var builtins = map[string]*object.Builtin{
 					return NULL
 				}
 
-				return &object.Array{
-					Elements: arg.Elements[1:len(arg.Elements)],
-				}
+				// The elements are copied so that assigning to the result doesn't change the original array.
+				elements := make([]object.Object, len(arg.Elements)-1)
+				copy(elements, arg.Elements[1:])
+
+				return &object.Array{Elements: elements}
 			default:
 				return newError("argument to `rest` not supported, got %s", args[0].Type())
 			}
 		},
 	},
+	// push never changes its argument. The elements are copied into a new slice rather than appended, since append can
+	// reuse the backing array and two pushes onto the same array would then overwrite each other.
 	"push": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) < 2 {
@@ -96,21 +142,956 @@ var builtins = map[string]*object.Builtin{
 
 			switch arg := args[0].(type) {
 			case *object.Array:
-				return &object.Array{
-					Elements: append(arg.Elements, args[1:]...),
+				elements := make([]object.Object, len(arg.Elements)+len(args)-1)
+				copy(elements, arg.Elements)
+				copy(elements[len(arg.Elements):], args[1:])
+
+				return &object.Array{Elements: elements}
+			}
+
+			return NULL
+		},
+	},
+	"concat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments. got=%d, want>=2", len(args))
+			}
+
+			length := 0
+			for _, arg := range args {
+				array, ok := arg.(*object.Array)
+				if !ok {
+					return newError("argument to `concat` not supported, got %s", arg.Type())
+				}
+
+				length += len(array.Elements)
+			}
+
+			elements := make([]object.Object, 0, length)
+			for _, arg := range args {
+				elements = append(elements, arg.(*object.Array).Elements...)
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	},
+	// repeat does not copy the value, so repeating an array or hash gives an array holding n references to the same object.
+	"repeat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			count, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("count passed to `repeat` must be INTEGER, got %s", args[1].Type())
+			}
+
+			if count.Value < 0 {
+				return newError("count passed to `repeat` cannot be negative, got %d", count.Value)
+			}
+
+			if count.Value > MaxRepeatLength {
+				return newError("count passed to `repeat` cannot be more than %d, got %d", MaxRepeatLength, count.Value)
+			}
+
+			elements := make([]object.Object, count.Value)
+			for i := range elements {
+				elements[i] = args[0]
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	},
+	// zip pairs up the elements of two arrays, stopping at the end of the shorter one.
+	"zip": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			for _, arg := range args {
+				if arg.Type() != object.ARRAY_OBJ {
+					return newError("argument to `zip` must be ARRAY, got %s", arg.Type())
+				}
+			}
+
+			a, b := args[0].(*object.Array), args[1].(*object.Array)
+
+			length := len(a.Elements)
+			if len(b.Elements) < length {
+				length = len(b.Elements)
+			}
+
+			pairs := make([]object.Object, length)
+			for i := range pairs {
+				pairs[i] = &object.Array{Elements: []object.Object{a.Elements[i], b.Elements[i]}}
+			}
+
+			return &object.Array{Elements: pairs}
+		},
+	},
+	"flatten": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `flatten` must be ARRAY, got %s", args[0].Type())
+			}
+
+			deep := false
+			if len(args) == 2 {
+				b, ok := args[1].(*object.Boolean)
+				if !ok {
+					return newError("second argument to `flatten` must be BOOLEAN, got %s", args[1].Type())
+				}
+
+				deep = b.Value
+			}
+
+			return &object.Array{Elements: flatten(arr.Elements, deep, []object.Object{})}
+		},
+	},
+	"unique": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument to `unique` must be ARRAY, got %s", args[0].Type())
+			}
+
+			// Hashable values are found with their hash key, and anything else, such as arrays, by comparing with every
+			// unhashable value seen so far.
+			seen := make(map[object.HashKey]bool)
+			others := []object.Object{}
+			elements := []object.Object{}
+
+			for _, e := range arr.Elements {
+				if hashable, ok := e.(object.Hashable); ok {
+					key := hashable.HashKey()
+					if seen[key] {
+						continue
+					}
+
+					seen[key] = true
+				} else {
+					if containsEqual(others, e) {
+						continue
+					}
+
+					others = append(others, e)
+				}
+
+				elements = append(elements, e)
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	},
+	"reverse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.String:
+				runes := []rune(arg.Value)
+				reversed := make([]rune, len(runes))
+				for i, r := range runes {
+					reversed[len(runes)-1-i] = r
+				}
+
+				return &object.String{Value: string(reversed)}
+			case *object.Array:
+				reversed := make([]object.Object, len(arg.Elements))
+				for i, e := range arg.Elements {
+					reversed[len(arg.Elements)-1-i] = e
+				}
+
+				return &object.Array{Elements: reversed}
+			default:
+				return newError("argument to `reverse` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"abs": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				if arg.Value < 0 {
+					return checkedIntegerObject(subInt64(0, arg.Value))
 				}
+
+				return arg
+			default:
+				return newError("argument to `abs` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"pow": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			base, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `pow` not supported, got %s", args[0].Type())
+			}
+
+			exponent, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("argument to `pow` not supported, got %s", args[1].Type())
+			}
+
+			if exponent.Value < 0 {
+				return newError("negative exponent: %d", exponent.Value)
+			}
+
+			return checkedIntegerObject(integerPower(base.Value, exponent.Value))
+		},
+	},
+	"sqrt": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			arg, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `sqrt` not supported, got %s", args[0].Type())
+			}
+
+			if arg.Value < 0 {
+				return newError("square root of negative number: %d", arg.Value)
+			}
+
+			return &object.Integer{Value: integerSqrt(arg.Value)}
+		},
+	},
+	"min": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("min", args, func(a, b int64) bool { return a < b })
+		},
+	},
+	"max": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return extremum("max", args, func(a, b int64) bool { return a > b })
+		},
+	},
+	"is_array": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return isType(args, object.ARRAY_OBJ)
+		},
+	},
+	"is_string": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return isType(args, object.STRING_OBJ)
+		},
+	},
+	"is_int": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return isType(args, object.INTEGER_OBJ)
+		},
+	},
+	// is_fn is true for builtins as well as functions, since both can be called in the same way.
+	"is_fn": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return isType(args, object.FUNCTION_OBJ, object.BUILTIN_OBJ)
+		},
+	},
+	"is_error": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return isType(args, object.ERROR_OBJ)
+		},
+	},
+	"error": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values, err := stringArgs("error", args, 1)
+			if err != nil {
+				return err
 			}
 
+			return &object.Error{Message: values[0], Constructed: true}
+		},
+	},
+	"trim": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return mapString("trim", args, strings.TrimSpace)
+		},
+	},
+	"upper": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return mapString("upper", args, strings.ToUpper)
+		},
+	},
+	"lower": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return mapString("lower", args, strings.ToLower)
+		},
+	},
+	// replace returns a copy of a string with every non-overlapping occurrence of old replaced by new. An empty old string
+	// is an error rather than inserting new between every character.
+	"replace": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values, err := stringArgs("replace", args, 3)
+			if err != nil {
+				return err
+			}
+
+			if values[1] == "" {
+				return newError("string to replace passed to `replace` cannot be empty")
+			}
+
+			return &object.String{Value: strings.ReplaceAll(values[0], values[1], values[2])}
+		},
+	},
+	"starts_with": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values, err := stringArgs("starts_with", args, 2)
+			if err != nil {
+				return err
+			}
+
+			return nativeBoolToBooleanObject(strings.HasPrefix(values[0], values[1]))
+		},
+	},
+	"ends_with": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values, err := stringArgs("ends_with", args, 2)
+			if err != nil {
+				return err
+			}
+
+			return nativeBoolToBooleanObject(strings.HasSuffix(values[0], values[1]))
+		},
+	},
+	// chars splits a string into its characters. Strings can hold Unicode, so it splits on runes rather than bytes: the
+	// result for "é" is ["é"], even though len("é") is 2.
+	"chars": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values, err := stringArgs("chars", args, 1)
+			if err != nil {
+				return err
+			}
+
+			elements := []object.Object{}
+			for _, r := range values[0] {
+				elements = append(elements, &object.String{Value: string(r)})
+			}
+
+			return &object.Array{Elements: elements}
+		},
+	},
+	"sum": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return reduceIntegers("sum", args, 0, addInt64)
+		},
+	},
+	"product": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			return reduceIntegers("product", args, 1, mulInt64)
+		},
+	},
+	"parse_json": &object.Builtin{
+		Fn: builtinParseJSON,
+	},
+	"to_json": &object.Builtin{
+		Fn: builtinToJSON,
+	},
+	"rand": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			n, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `rand` not supported, got %s", args[0].Type())
+			}
+
+			if n.Value <= 0 {
+				return newError("argument to `rand` must be positive, got %d", n.Value)
+			}
+
+			return &object.Integer{Value: random.Int63n(n.Value)}
+		},
+	},
+	"seed": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			n, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `seed` not supported, got %s", args[0].Type())
+			}
+
+			random.Seed(n.Value)
+
 			return NULL
 		},
 	},
+	"input": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+
+			if len(args) == 1 {
+				prompt, ok := args[0].(*object.String)
+				if !ok {
+					return newError("argument to `input` must be STRING, got %s", args[0].Type())
+				}
+
+				fmt.Fprint(output, prompt.Value)
+			}
+
+			line, err := inputReader.ReadString('\n')
+			if err == io.EOF && line == "" {
+				return NULL
+			} else if err != nil && err != io.EOF {
+				return newError("could not read input: %s", err)
+			}
+
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+
+			return &object.String{Value: line}
+		},
+	},
+	"clock": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("wrong number of arguments. got=%d, want=0", len(args))
+			}
+
+			return &object.Integer{Value: nowFunc().UnixNano() / int64(time.Millisecond)}
+		},
+	},
+	"exit": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) > 1 {
+				return newError("wrong number of arguments. got=%d, want=0 or 1", len(args))
+			}
+
+			if len(args) == 0 {
+				return &object.Exit{Code: 0}
+			}
+
+			code, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `exit` must be INTEGER, got %s", args[0].Type())
+			}
+
+			return &object.Exit{Code: code.Value}
+		},
+	},
+	"assert": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+			}
+
+			if isTruthy(args[0]) {
+				return NULL
+			}
+
+			if len(args) == 2 {
+				message, ok := args[1].(*object.String)
+				if !ok {
+					return newError("message passed to `assert` must be STRING, got %s", args[1].Type())
+				}
+
+				return newError("assertion failed: %s", message.Value)
+			}
+
+			return newError("assertion failed")
+		},
+	},
+	"format": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments. got=0, want>=1")
+			}
+
+			format, ok := args[0].(*object.String)
+			if !ok {
+				return newError("first argument to `format` must be STRING, got %s", args[0].Type())
+			}
+
+			values := args[1:]
+			if placeholders := strings.Count(format.Value, "{}"); placeholders != len(values) {
+				return newError("wrong number of values for format string. got=%d, want=%d", len(values), placeholders)
+			}
+
+			var out strings.Builder
+			parts := strings.Split(format.Value, "{}")
+			for i, part := range parts {
+				out.WriteString(part)
+
+				if i < len(values) {
+					out.WriteString(values[i].Inspect())
+				}
+			}
+
+			return &object.String{Value: out.String()}
+		},
+	},
+	"ord": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `ord` not supported, got %s", args[0].Type())
+			}
+
+			if len(str.Value) != 1 {
+				return newError("argument to `ord` must be a single character, got %q", str.Value)
+			}
+
+			return &object.Integer{Value: int64(str.Value[0])}
+		},
+	},
+	"chr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			code, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("argument to `chr` not supported, got %s", args[0].Type())
+			}
+
+			if code.Value < 0 || code.Value > 255 {
+				return newError("argument to `chr` out of range: %d", code.Value)
+			}
+
+			return &object.String{Value: string([]byte{byte(code.Value)})}
+		},
+	},
+	"parse_int": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `parse_int` must be STRING, got %s", args[0].Type())
+			}
+
+			base, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("base passed to `parse_int` must be INTEGER, got %s", args[1].Type())
+			}
+
+			if base.Value < 2 || base.Value > 36 {
+				return newError("base passed to `parse_int` out of range: %d, want 2 to 36", base.Value)
+			}
+
+			value, err := strconv.ParseInt(str.Value, int(base.Value), 64)
+			if errors.Is(err, strconv.ErrRange) {
+				return newError("integer overflow")
+			} else if err != nil {
+				return newError("could not parse %q as an integer in base %d", str.Value, base.Value)
+			}
+
+			return &object.Integer{Value: value}
+		},
+	},
+	"has": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return newError("first argument to `has` must be HASH, got %s", args[0].Type())
+			}
+
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", args[1].Type())
+			}
+
+			_, ok = hash.Pairs[key.HashKey()]
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+	// delete never changes its argument. It returns a copy of the hash without the given key, or a copy of the array
+	// without the element at the given index.
+	"delete": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			switch collection := args[0].(type) {
+			case *object.Array:
+				index, ok := args[1].(*object.Integer)
+				if !ok {
+					return newError("array index must be INTEGER, got %s", args[1].Type())
+				}
+
+				idx, ok := resolveIndex(index.Value, len(collection.Elements))
+				if !ok {
+					return newError("index out of range: %d", index.Value)
+				}
+
+				elements := make([]object.Object, 0, len(collection.Elements)-1)
+				elements = append(elements, collection.Elements[:idx]...)
+				elements = append(elements, collection.Elements[idx+1:]...)
+
+				return &object.Array{Elements: elements}
+			case *object.Hash:
+				key, ok := args[1].(object.Hashable)
+				if !ok {
+					return newError("unusable as hash key: %s", args[1].Type())
+				}
+
+				pairs := make(map[object.HashKey]object.HashPair, len(collection.Pairs))
+				for k, pair := range collection.Pairs {
+					pairs[k] = pair
+				}
+
+				delete(pairs, key.HashKey())
+
+				return &object.Hash{Pairs: pairs}
+			default:
+				return newError("argument to `delete` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	// index_of finds the first element of an array equal to the value, or the byte index of the first occurrence of a
+	// substring in a string. It returns -1 if there isn't one.
+	"index_of": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("wrong number of arguments. got=%d, want=2", len(args))
+			}
+
+			switch collection := args[0].(type) {
+			case *object.Array:
+				for i, e := range collection.Elements {
+					if object.Equals(e, args[1]) {
+						return &object.Integer{Value: int64(i)}
+					}
+				}
+
+				return &object.Integer{Value: -1}
+			case *object.String:
+				substring, ok := args[1].(*object.String)
+				if !ok {
+					return newError("substring passed to `index_of` must be STRING, got %s", args[1].Type())
+				}
+
+				return &object.Integer{Value: int64(strings.Index(collection.Value, substring.Value))}
+			default:
+				return newError("argument to `index_of` not supported, got %s", args[0].Type())
+			}
+		},
+	},
+	"slice": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("wrong number of arguments. got=%d, want=2 or 3", len(args))
+			}
+
+			switch args[0].(type) {
+			case *object.Array, *object.String:
+			default:
+				return newError("argument to `slice` not supported, got %s", args[0].Type())
+			}
+
+			var high object.Object
+			if len(args) == 3 {
+				high = args[2]
+			}
+
+			return sliceObject(args[0], args[1], high)
+		},
+	},
 	"puts": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
-				fmt.Println(arg.Inspect())
+				fmt.Fprintln(output, arg.Inspect())
 			}
 
 			return NULL
 		},
 	},
+	"print": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			values := make([]string, len(args))
+			for i, arg := range args {
+				values[i] = arg.Inspect()
+			}
+
+			fmt.Fprint(output, strings.Join(values, " "))
+
+			return NULL
+		},
+	},
+}
+
+// extremum returns the argument which is better than every other argument according to the better function. It is used
+// to implement the `min` and `max` builtins, with name being the builtin's name for error messages.
+func extremum(name string, args []object.Object, better func(a, b int64) bool) object.Object {
+	if len(args) == 0 {
+		return newError("wrong number of arguments. got=0, want>=1")
+	}
+
+	var result *object.Integer
+	for _, arg := range args {
+		integer, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("argument to `%s` not supported, got %s", name, arg.Type())
+		}
+
+		if result == nil || better(integer.Value, result.Value) {
+			result = integer
+		}
+	}
+
+	return result
+}
+
+// reduceIntegers combines every integer in an array argument using op, starting from initial, so an empty array gives
+// initial. It is used to implement the `sum` and `product` builtins, with name being the builtin's name for error
+// messages.
+func reduceIntegers(name string, args []object.Object, initial int64, op func(a, b int64) (int64, bool)) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+
+	result := initial
+	for _, e := range arr.Elements {
+		integer, ok := e.(*object.Integer)
+		if !ok {
+			return newError("array passed to `%s` must only contain INTEGER, got %s", name, e.Type())
+		}
+
+		if result, ok = op(result, integer.Value); !ok {
+			return newError("integer overflow")
+		}
+	}
+
+	return nativeIntToIntegerObject(result)
+}
+
+// integerSqrt returns the square root of a non-negative integer, rounded down. math.Sqrt is only used as an estimate,
+// since large integers can't be represented exactly as floats.
+func integerSqrt(n int64) int64 {
+	root := int64(math.Sqrt(float64(n)))
+
+	for root > 0 && root > n/root {
+		root--
+	}
+
+	for root+1 <= n/(root+1) {
+		root++
+	}
+
+	return root
+}
+
+// flatten appends the elements to result, replacing each array with its elements. If deep is true, arrays inside those
+// arrays are flattened too, otherwise only one level is removed.
+func flatten(elements []object.Object, deep bool, result []object.Object) []object.Object {
+	for _, e := range elements {
+		inner, ok := e.(*object.Array)
+		switch {
+		case !ok:
+			result = append(result, e)
+		case deep:
+			result = flatten(inner.Elements, true, result)
+		default:
+			result = append(result, inner.Elements...)
+		}
+	}
+
+	return result
+}
+
+// containsEqual returns true if any of the objects is equal to target, in the same way as "==".
+func containsEqual(objects []object.Object, target object.Object) bool {
+	for _, o := range objects {
+		if object.Equals(o, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stringArgs checks that there are exactly want arguments and that they are all strings, returning their values. If they
+// aren't, the error to return from the builtin is returned instead. name is the builtin's name for error messages.
+func stringArgs(name string, args []object.Object, want int) ([]string, object.Object) {
+	if len(args) != want {
+		return nil, newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		str, ok := arg.(*object.String)
+		if !ok {
+			return nil, newError("argument to `%s` must be STRING, got %s", name, arg.Type())
+		}
+
+		values[i] = str.Value
+	}
+
+	return values, nil
+}
+
+// mapString returns the result of calling fn on a single string argument. It is used to implement builtins such as
+// `upper`, with name being the builtin's name for error messages.
+func mapString(name string, args []object.Object, fn func(string) string) object.Object {
+	values, err := stringArgs(name, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return &object.String{Value: fn(values[0])}
+}
+
+// isType returns TRUE if the single argument has one of the given types, and FALSE if it doesn't. It is used to
+// implement the `is_` builtins, such as `is_array`.
+func isType(args []object.Object, types ...object.ObjectType) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	for _, t := range types {
+		if args[0].Type() == t {
+			return TRUE
+		}
+	}
+
+	return FALSE
+}
+
+// init registers the builtins which call back into the evaluator. Defining these inside the builtins map directly would
+// create an initialization cycle, since the evaluator looks up builtins in that map.
+func init() {
+	builtins["sort"] = &object.Builtin{EnvFn: builtinSort}
+	builtins["env"] = &object.Builtin{EnvFn: builtinEnv}
+}
+
+// builtinSort returns a sorted copy of an array. Without a comparator, the array must contain only integers or only
+// strings. With a comparator fn(a, b), a is placed before b when the comparator returns a truthy value.
+func builtinSort(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("wrong number of arguments. got=%d, want=1 or 2", len(args))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `sort` must be ARRAY, got %s", args[0].Type())
+	}
+
+	elements := make([]object.Object, len(arr.Elements))
+	copy(elements, arr.Elements)
+
+	if len(args) == 2 {
+		return sortWithComparator(elements, args[1], env)
+	}
+
+	if len(elements) == 0 {
+		return &object.Array{Elements: elements}
+	}
+
+	elementType := elements[0].Type()
+	for _, e := range elements {
+		if e.Type() != elementType {
+			return newError("cannot sort array with mixed types: %s and %s", elementType, e.Type())
+		}
+	}
+
+	switch elementType {
+	case object.INTEGER_OBJ:
+		sort.SliceStable(elements, func(i, j int) bool {
+			return elements[i].(*object.Integer).Value < elements[j].(*object.Integer).Value
+		})
+	case object.STRING_OBJ:
+		sort.SliceStable(elements, func(i, j int) bool {
+			return elements[i].(*object.String).Value < elements[j].(*object.String).Value
+		})
+	default:
+		return newError("cannot sort array of %s without a comparator", elementType)
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// sortWithComparator sorts the elements in place using a Monkey function as the comparator. The first error returned by
+// the comparator stops the sort and is returned instead of the array.
+func sortWithComparator(elements []object.Object, comparator object.Object, env *object.Environment) object.Object {
+	if comparator.Type() != object.FUNCTION_OBJ && comparator.Type() != object.BUILTIN_OBJ {
+		return newError("comparator passed to `sort` must be FUNCTION, got %s", comparator.Type())
+	}
+
+	var err object.Object
+
+	sort.SliceStable(elements, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+
+		result := applyFunction(comparator, []object.Object{elements[i], elements[j]}, env)
+		if isError(result) {
+			err = result
+			return false
+		}
+
+		return isTruthy(result)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// builtinEnv returns a hash mapping the name of every identifier visible from where it is called to its value. It is
+// meant for debugging, and doesn't include builtins.
+func builtinEnv(env *object.Environment, args ...object.Object) object.Object {
+	if len(args) != 0 {
+		return newError("wrong number of arguments. got=%d, want=0", len(args))
+	}
+
+	pairs := make(map[object.HashKey]object.HashPair)
+	for _, name := range env.Names() {
+		value, _ := env.Get(name)
+		key := &object.String{Value: name}
+
+		pairs[key.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
 }
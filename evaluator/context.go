@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"context"
+
+	"github.com/ollybritton/monkey/ast"
+	"github.com/ollybritton/monkey/object"
+)
+
+// evalState is the state of a single call to Eval or EvalWithContext. It is attached to the environments used by that
+// evaluation rather than kept in a global, so that programs can be evaluated concurrently.
+type evalState struct {
+	// ctx is the context passed to EvalWithContext, or nil for Eval. It is checked on every loop iteration and function
+	// call, which is where a program can spend an unbounded amount of time.
+	ctx context.Context
+
+	// depth is the number of function calls currently being evaluated.
+	depth int
+}
+
+// EvalWithContext evaluates a node like Eval, but stops with the error "evaluation cancelled" once the context is
+// cancelled or its deadline passes. This bounds how long untrusted programs can run for.
+func EvalWithContext(ctx context.Context, node ast.Node, env *object.Environment) object.Object {
+	return Eval(node, env.WithEvalState(&evalState{ctx: ctx}))
+}
+
+// withState returns the environment with evaluation state attached, starting a new evaluation if it doesn't have any.
+func withState(env *object.Environment) *object.Environment {
+	if env == nil || env.EvalState() != nil {
+		return env
+	}
+
+	return env.WithEvalState(&evalState{})
+}
+
+// stateOf returns the state of the evaluation the environment is being used in.
+func stateOf(env *object.Environment) *evalState {
+	if state, ok := env.EvalState().(*evalState); ok {
+		return state
+	}
+
+	return &evalState{}
+}
+
+// cancelled reports whether the context of the evaluation the environment is being used in has been cancelled.
+func cancelled(env *object.Environment) bool {
+	ctx := stateOf(env).ctx
+	if ctx == nil {
+		return false
+	}
+
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
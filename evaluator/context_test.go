@@ -0,0 +1,71 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/parser"
+)
+
+func TestEvalWithContext(t *testing.T) {
+	tests := []string{
+		"for (;;) {}",
+		"let i = 0; do { i = i + 1 } while (true)",
+		"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) + f(n - 1) } }; f(100)",
+	}
+
+	for _, input := range tests {
+		program := parser.New(lexer.New(input)).ParseProgram()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		evaluated := EvalWithContext(ctx, program, object.NewEnvironment())
+		cancel()
+
+		testErrorObject(t, evaluated, "evaluation cancelled")
+	}
+}
+
+func TestEvalWithContextConcurrent(t *testing.T) {
+	loop := parser.New(lexer.New("for (;;) {}")).ParseProgram()
+	sum := parser.New(lexer.New("let sum = 0; for (let i = 0; i < 100; i = i + 1) { sum = sum + i }; sum")).ParseProgram()
+
+	// A cancelled evaluation finishing while another is still running shouldn't affect any later evaluations.
+	long, cancelLong := context.WithCancel(context.Background())
+	done := make(chan object.Object)
+	go func() { done <- EvalWithContext(long, loop, object.NewEnvironment()) }()
+
+	short, cancelShort := context.WithCancel(context.Background())
+	cancelShort()
+	testErrorObject(t, EvalWithContext(short, loop, object.NewEnvironment()), "evaluation cancelled")
+
+	testIntegerObject(t, Eval(sum, object.NewEnvironment()), 4950)
+
+	cancelLong()
+	testErrorObject(t, <-done, "evaluation cancelled")
+
+	testIntegerObject(t, Eval(sum, object.NewEnvironment()), 4950)
+}
+
+func TestEvalWithContextClosure(t *testing.T) {
+	env := object.NewEnvironment()
+
+	// A function defined during a cancelled evaluation can still be called from a later one.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	program := parser.New(lexer.New("let f = fn(n) { if (n == 0) { 0 } else { n + f(n - 1) } }; for (;;) {}")).ParseProgram()
+	testErrorObject(t, EvalWithContext(ctx, program, env), "evaluation cancelled")
+
+	call := parser.New(lexer.New("f(10)")).ParseProgram()
+	testIntegerObject(t, Eval(call, env), 55)
+}
+
+func TestEvalWithContextCompletes(t *testing.T) {
+	program := parser.New(lexer.New("let sum = 0; for (let i = 0; i < 10; i = i + 1) { sum = sum + i }; sum")).ParseProgram()
+
+	evaluated := EvalWithContext(context.Background(), program, object.NewEnvironment())
+	testIntegerObject(t, evaluated, 45)
+}
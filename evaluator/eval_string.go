@@ -0,0 +1,34 @@
+package evaluator
+
+import (
+	"errors"
+
+	"github.com/ollybritton/monkey/lexer"
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/parser"
+)
+
+// EvalString lexes, parses and evaluates a program in the given environment, expanding any macros it defines first. If
+// the program can't be parsed, the error is a parser.ParseErrors. If evaluating the program gives an *object.Error, it
+// is returned as a Go error instead of as the result.
+func EvalString(src string, env *object.Environment) (object.Object, error) {
+	program, err := parser.New(lexer.New(src)).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	macroEnv := object.NewEnvironment()
+	DefineMacros(program, macroEnv)
+
+	expanded, err := ExpandMacros(program, macroEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Eval(expanded, env)
+	if errObj, ok := result.(*object.Error); ok {
+		return nil, errors.New(errObj.Message)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/parser"
+)
+
+func TestEvalString(t *testing.T) {
+	env := object.NewEnvironment()
+
+	result, err := EvalString("let double = fn(x) { x * 2 }; double(21)", env)
+	if err != nil {
+		t.Fatalf("EvalString returned error: %s", err)
+	}
+
+	testIntegerObject(t, result, 42)
+
+	// The environment is shared between calls, so later programs can use earlier bindings.
+	result, err = EvalString("double(5)", env)
+	if err != nil {
+		t.Fatalf("EvalString returned error: %s", err)
+	}
+
+	testIntegerObject(t, result, 10)
+}
+
+func TestEvalStringParseError(t *testing.T) {
+	result, err := EvalString("let = 5;", object.NewEnvironment())
+	if result != nil {
+		t.Errorf("result should be nil. got=%T (%+v)", result, result)
+	}
+
+	if _, ok := err.(parser.ParseErrors); !ok {
+		t.Fatalf("err is not parser.ParseErrors. got=%T (%+v)", err, err)
+	}
+
+	if err.Error() != "expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+}
+
+func TestEvalStringRuntimeError(t *testing.T) {
+	result, err := EvalString("1 + true", object.NewEnvironment())
+	if result != nil {
+		t.Errorf("result should be nil. got=%T (%+v)", result, result)
+	}
+
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if err.Error() != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", err.Error())
+	}
+}
+
+func TestEvalStringWithGoValues(t *testing.T) {
+	env := object.NewEnvironment()
+
+	for name, value := range map[string]interface{}{"debug": false, "ports": []int{80, 443}} {
+		obj, err := object.FromGo(value)
+		if err != nil {
+			t.Fatalf("FromGo returned error: %s", err)
+		}
+
+		env.Set(name, obj)
+	}
+
+	result, err := EvalString(`if (debug) { 0 } else { ports[1] }`, env)
+	if err != nil {
+		t.Fatalf("EvalString returned error: %s", err)
+	}
+
+	if object.ToGo(result) != int64(443) {
+		t.Errorf("wrong result. got=%#v", object.ToGo(result))
+	}
+
+	result, err = EvalString(`!debug`, env)
+	if err != nil {
+		t.Fatalf("EvalString returned error: %s", err)
+	}
+
+	testBooleanObject(t, result, true)
+}
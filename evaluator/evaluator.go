@@ -2,9 +2,13 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 
 	"github.com/ollybritton/monkey/ast"
 	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/token"
 )
 
 // Reused objects with a fixed number of values.
@@ -12,14 +16,44 @@ var (
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
 	NULL  = &object.Null{}
+
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
+)
+
+// MaxCallDepth is the maximum number of nested function calls allowed before evaluation is stopped with an error. This
+// stops infinitely recursive functions from overflowing the Go stack and crashing the interpreter.
+var MaxCallDepth = 5000
+
+// MaxRepeatLength is the maximum length of a string or array which can be made by repeating one. Repeating is the easiest
+// way for a program to ask for an enormous amount of memory, so it is limited to stop that from crashing the interpreter.
+var MaxRepeatLength int64 = 1 << 24
+
+// Bounds of the range of integers which are cached. Integers are immutable, so every integer in this range can share a
+// single object instead of allocating a new one each time it is produced.
+const (
+	minCachedInteger = -128
+	maxCachedInteger = 255
 )
 
+// integerCache holds the shared objects for the integers between minCachedInteger and maxCachedInteger.
+var integerCache = func() []*object.Integer {
+	cache := make([]*object.Integer, maxCachedInteger-minCachedInteger+1)
+	for i := range cache {
+		cache[i] = &object.Integer{Value: int64(i + minCachedInteger)}
+	}
+
+	return cache
+}()
+
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
 // Eval evaluates an AST node and returns an object.Object representation of the result.
 func Eval(node ast.Node, environment *object.Environment) object.Object {
+	environment = withState(environment)
+
 	switch node := node.(type) {
 	// Statements
 	case *ast.Program:
@@ -36,12 +70,34 @@ func Eval(node ast.Node, environment *object.Environment) object.Object {
 
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
+		if node.Pattern != nil {
+			return evalDestructuringLetStatement(node, environment)
+		}
+
+		if environment.IsLocalConstant(node.Name.Value) {
+			return newError("cannot assign to constant: %s", node.Name.Value)
+		}
+
 		val := Eval(node.Value, environment)
 		if isError(val) {
 			return val
 		}
 
-		environment.Set(node.Name.Value, val)
+		if node.Constant {
+			return environment.SetConstant(node.Name.Value, val)
+		}
+
+		return environment.Set(node.Name.Value, val)
+	case *ast.ForStatement:
+		return evalForStatement(node, environment)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, environment)
+	case *ast.DoWhileStatement:
+		return evalDoWhileStatement(node, environment)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 
 	// Expressions
 	case *ast.PrefixExpression:
@@ -63,23 +119,46 @@ func Eval(node ast.Node, environment *object.Environment) object.Object {
 		}
 
 		return evalInfixExpression(node.Operator, left, right)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, environment)
+	case *ast.CompoundAssignExpression:
+		return evalCompoundAssignExpression(node, environment)
+	case *ast.PostfixExpression:
+		return evalPostfixExpression(node, environment)
 	case *ast.IfExpression:
 		return evalIfExpression(node, environment)
+	case *ast.SwitchExpression:
+		return evalSwitchExpression(node, environment)
+	case *ast.TryExpression:
+		return evalTryExpression(node, environment)
+	case *ast.TernaryExpression:
+		return evalTernaryExpression(node, environment)
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return nativeIntToIntegerObject(node.Value)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return NULL
 	case *ast.Identifier:
 		return evalIdentifier(node, environment)
 	case *ast.FunctionLiteral:
 		return &object.Function{
 			Parameters: node.Parameters,
+			Defaults:   node.Defaults,
 			Env:        environment,
 			Body:       node.Body,
 		}
 	case *ast.CallExpression:
+		if node.Function.TokenLiteral() == "quote" {
+			if len(node.Arguments) != 1 {
+				return newError("wrong number of arguments to `quote`. got=%d, want=1", len(node.Arguments))
+			}
+
+			return quote(node.Arguments[0], environment)
+		}
+
 		function := Eval(node.Function, environment)
 		if isError(function) {
 			return function
@@ -90,7 +169,7 @@ func Eval(node ast.Node, environment *object.Environment) object.Object {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, environment)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, environment)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -106,15 +185,21 @@ func Eval(node ast.Node, environment *object.Environment) object.Object {
 			return left
 		}
 
+		if node.Token.Type == token.DOT && left.Type() != object.HASH_OBJ {
+			return newError("member access not supported: %s", left.Type())
+		}
+
 		index := Eval(node.Index, environment)
 		if isError(index) {
 			return index
 		}
 
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, environment)
 	}
 
-	return nil
+	return newError("unknown node type: %T", node)
 }
 
 func evalProgram(stmts []ast.Statement, environment *object.Environment) object.Object {
@@ -127,22 +212,32 @@ func evalProgram(stmts []ast.Statement, environment *object.Environment) object.
 		case *object.ReturnValue:
 			return result.Value
 		case *object.Error:
+			if !result.Constructed {
+				return result
+			}
+		case *object.Exit:
 			return result
+		case *object.Break:
+			return newError("break outside of loop")
+		case *object.Continue:
+			return newError("continue outside of loop")
 		}
 	}
 
 	return result
 }
 
+// evalBlockStatement evaluates the statements in a block, returning the value of the last one. An empty block evaluates
+// to NULL.
 func evalBlockStatement(block *ast.BlockStatement, environment *object.Environment) object.Object {
-	var result object.Object
+	var result object.Object = NULL
 
 	for _, statement := range block.Statements {
 		result = Eval(statement, environment)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ || isError(result) {
 				return result
 			}
 		}
@@ -151,6 +246,163 @@ func evalBlockStatement(block *ast.BlockStatement, environment *object.Environme
 	return result
 }
 
+// evalForStatement evaluates a C-style for loop. The init statement is evaluated once in a new scope, and then the body
+// and post expression are evaluated for as long as the condition is truthy. A break stops the loop, and a continue skips
+// the rest of the body but still evaluates the post expression.
+func evalForStatement(fs *ast.ForStatement, environment *object.Environment) object.Object {
+	loopEnv := object.NewExtendedEnvironment(environment)
+
+	if fs.Init != nil {
+		init := Eval(fs.Init, loopEnv)
+		if init != nil && isError(init) {
+			return init
+		}
+	}
+
+	for {
+		if cancelled(environment) {
+			return newError("evaluation cancelled")
+		}
+
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+
+			if !isTruthy(condition) {
+				break
+			}
+		}
+
+		result := Eval(fs.Body, loopEnv)
+		if result == BREAK {
+			break
+		}
+
+		if result != nil && result != CONTINUE {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || isError(result) {
+				return result
+			}
+		}
+
+		if fs.Post != nil {
+			post := Eval(fs.Post, loopEnv)
+			if isError(post) {
+				return post
+			}
+		}
+	}
+
+	return NULL
+}
+
+// evalDoWhileStatement evaluates a loop which runs its body and then stops once its condition is no longer truthy, so
+// the body is always run at least once. A continue statement skips straight to checking the condition.
+func evalDoWhileStatement(dws *ast.DoWhileStatement, environment *object.Environment) object.Object {
+	for {
+		if cancelled(environment) {
+			return newError("evaluation cancelled")
+		}
+
+		result := Eval(dws.Body, environment)
+		if result == BREAK {
+			break
+		}
+
+		if result != nil && result != CONTINUE {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || isError(result) {
+				return result
+			}
+		}
+
+		condition := Eval(dws.Condition, environment)
+		if isError(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			break
+		}
+	}
+
+	return NULL
+}
+
+// evalForInStatement evaluates a loop over the items in an array, string or hash. The loop variables are bound in a new
+// scope for each iteration. With a single variable, it is bound to each element of an array, each character of a string
+// or each key of a hash. With two variables, the first is bound to the index (or the key for a hash) and the second to
+// the element (or the value for a hash). The index of a character in a string is its byte offset, like the indexes used
+// by "s[i]" and index_of. Hashes are iterated in order of their keys' string representations.
+func evalForInStatement(fis *ast.ForInStatement, environment *object.Environment) object.Object {
+	iterable := Eval(fis.Iterable, environment)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var keys, values []object.Object
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for i, e := range iterable.Elements {
+			keys = append(keys, nativeIntToIntegerObject(int64(i)))
+			values = append(values, e)
+		}
+	case *object.String:
+		for i, r := range iterable.Value {
+			keys = append(keys, nativeIntToIntegerObject(int64(i)))
+			values = append(values, &object.String{Value: string(r)})
+		}
+	case *object.Hash:
+		pairs := make([]object.HashPair, 0, len(iterable.Pairs))
+		for _, pair := range iterable.Pairs {
+			pairs = append(pairs, pair)
+		}
+
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key.Inspect() < pairs[j].Key.Inspect() })
+
+		for _, pair := range pairs {
+			keys = append(keys, pair.Key)
+			values = append(values, pair.Value)
+		}
+
+		// With a single variable, a hash is iterated over its keys rather than its values.
+		if fis.Key == nil {
+			values = keys
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for i := range values {
+		if cancelled(environment) {
+			return newError("evaluation cancelled")
+		}
+
+		iterationEnv := object.NewExtendedEnvironment(environment)
+		if fis.Key != nil {
+			iterationEnv.Set(fis.Key.Value, keys[i])
+		}
+
+		iterationEnv.Set(fis.Value.Value, values[i])
+
+		result := Eval(fis.Body, iterationEnv)
+		if result == BREAK {
+			break
+		}
+
+		if result != nil && result != CONTINUE {
+			if result.Type() == object.RETURN_VALUE_OBJ || isError(result) {
+				return result
+			}
+		}
+	}
+
+	return NULL
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
@@ -179,6 +431,10 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 
 func evalInfixExpression(operator string, left object.Object, right object.Object) object.Object {
 	switch {
+	case operator == "*" && left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
+		return repeatString(left.(*object.String), right.(*object.Integer))
+	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
+		return repeatString(right.(*object.String), left.(*object.Integer))
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
@@ -186,9 +442,9 @@ func evalInfixExpression(operator string, left object.Object, right object.Objec
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
+		return nativeBoolToBooleanObject(object.Equals(left, right))
 	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
+		return nativeBoolToBooleanObject(!object.Equals(left, right))
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
@@ -209,26 +465,274 @@ func evalIfExpression(ie *ast.IfExpression, environment *object.Environment) obj
 	}
 }
 
-func evalBangOperatorExpression(right object.Object) object.Object {
-	switch right {
-	case TRUE:
-		return FALSE
-	case FALSE:
-		return TRUE
-	case NULL:
-		return TRUE
+// evalSwitchExpression evaluates the body of the first case with a value equal to the subject, or the default case if
+// none of them have one. The values of a case are evaluated in order, stopping at the first match. Values are compared
+// like "==", except that values of different types are never equal rather than being an error. If no case matches and
+// there is no default, the result is NULL.
+func evalSwitchExpression(se *ast.SwitchExpression, environment *object.Environment) object.Object {
+	subject := Eval(se.Subject, environment)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, c := range se.Cases {
+		for _, v := range c.Values {
+			value := Eval(v, environment)
+			if isError(value) {
+				return value
+			}
+
+			if object.Equals(subject, value) {
+				return Eval(c.Body, environment)
+			}
+		}
+	}
+
+	if se.Default != nil {
+		return Eval(se.Default, environment)
+	}
+
+	return NULL
+}
+
+// evalTryExpression evaluates the try block, and if it produces an error, evaluates the catch block in a new scope with
+// the error message bound to the catch parameter. Anything else the try block produces, including a return value, a call
+// to exit or an error made by the `error` builtin which hasn't been returned from a function, is passed through.
+func evalTryExpression(te *ast.TryExpression, environment *object.Environment) object.Object {
+	result := Eval(te.Body, environment)
+
+	err, ok := result.(*object.Error)
+	if !ok || err.Constructed {
+		return result
+	}
+
+	catchEnv := object.NewExtendedEnvironment(environment)
+	catchEnv.Set(te.Parameter.Value, &object.String{Value: err.Message})
+
+	return Eval(te.Catch, catchEnv)
+}
+
+// evalDestructuringLetStatement binds each name in the pattern of a let statement to the matching element of an array,
+// and returns the array. The array must have exactly as many elements as there are names.
+func evalDestructuringLetStatement(node *ast.LetStatement, environment *object.Environment) object.Object {
+	for _, name := range node.Pattern.Elements {
+		if environment.IsLocalConstant(name.Value) {
+			return newError("cannot assign to constant: %s", name.Value)
+		}
+	}
+
+	val := Eval(node.Value, environment)
+	if isError(val) {
+		return val
+	}
+
+	array, ok := val.(*object.Array)
+	if !ok {
+		return newError("cannot destructure %s, want ARRAY", val.Type())
+	}
+
+	if len(array.Elements) != len(node.Pattern.Elements) {
+		return newError("wrong number of values to destructure. got=%d, want=%d", len(array.Elements), len(node.Pattern.Elements))
+	}
+
+	for i, name := range node.Pattern.Elements {
+		if node.Constant {
+			environment.SetConstant(name.Value, array.Elements[i])
+		} else {
+			environment.Set(name.Value, array.Elements[i])
+		}
+	}
+
+	return array
+}
+
+// evalPostfixExpression increments or decrements an integer variable in place. Like in C, the value of the expression
+// is the value the variable had before it was changed.
+func evalPostfixExpression(node *ast.PostfixExpression, environment *object.Environment) object.Object {
+	name := node.Target.Value
+	if environment.IsConstant(name) {
+		return newError("cannot assign to constant: %s", name)
+	}
+
+	value, ok := environment.Get(name)
+	if !ok {
+		return newError("identifier not found: " + name)
+	}
+
+	integer, ok := value.(*object.Integer)
+	if !ok {
+		return newError("unknown operator: %s%s", value.Type(), node.Operator)
+	}
+
+	var result object.Object
+	if node.Operator == "++" {
+		result = checkedIntegerObject(addInt64(integer.Value, 1))
+	} else {
+		result = checkedIntegerObject(subInt64(integer.Value, 1))
+	}
+
+	if isError(result) {
+		return result
+	}
+
+	environment.Assign(name, result)
+	return integer
+}
+
+// evalAssignExpression assigns a new value to an identifier, an element of an array or a key in a hash, returning the
+// value assigned. Identifiers must already have been bound with let.
+func evalAssignExpression(node *ast.AssignExpression, environment *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		if environment.IsConstant(target.Value) {
+			return newError("cannot assign to constant: %s", target.Value)
+		}
+
+		value := Eval(node.Value, environment)
+		if isError(value) {
+			return value
+		}
+
+		if !environment.Assign(target.Value, value) {
+			return newError("identifier not found: " + target.Value)
+		}
+
+		return value
+	case *ast.IndexExpression:
+		left := Eval(target.Left, environment)
+		if isError(left) {
+			return left
+		}
+
+		index := Eval(target.Index, environment)
+		if isError(index) {
+			return index
+		}
+
+		value := Eval(node.Value, environment)
+		if isError(value) {
+			return value
+		}
+
+		return evalIndexAssignment(left, index, value)
 	default:
-		return FALSE
+		return newError("invalid assignment target: %s", node.Target.String())
 	}
 }
 
+// evalCompoundAssignExpression applies an arithmetic operator to the current value of an identifier, an element of an
+// array or a key in a hash and assigns the result back, returning the value assigned. The target's collection and index
+// are only evaluated once.
+func evalCompoundAssignExpression(node *ast.CompoundAssignExpression, environment *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		if environment.IsConstant(target.Value) {
+			return newError("cannot assign to constant: %s", target.Value)
+		}
+
+		current, ok := environment.Get(target.Value)
+		if !ok {
+			return newError("identifier not found: " + target.Value)
+		}
+
+		value := evalCompoundValue(node, current, environment)
+		if isError(value) {
+			return value
+		}
+
+		environment.Assign(target.Value, value)
+		return value
+	case *ast.IndexExpression:
+		left := Eval(target.Left, environment)
+		if isError(left) {
+			return left
+		}
+
+		index := Eval(target.Index, environment)
+		if isError(index) {
+			return index
+		}
+
+		current := evalIndexExpression(left, index)
+		if isError(current) {
+			return current
+		}
+
+		value := evalCompoundValue(node, current, environment)
+		if isError(value) {
+			return value
+		}
+
+		return evalIndexAssignment(left, index, value)
+	default:
+		return newError("invalid assignment target: %s", node.Target.String())
+	}
+}
+
+// evalCompoundValue evaluates the right hand side of a compound assignment and applies its operator to the current
+// value of the target.
+func evalCompoundValue(node *ast.CompoundAssignExpression, current object.Object, environment *object.Environment) object.Object {
+	right := Eval(node.Value, environment)
+	if isError(right) {
+		return right
+	}
+
+	return evalInfixExpression(node.Operator, current, right)
+}
+
+// evalIndexAssignment sets the value at an index of a collection, changing the collection in place.
+func evalIndexAssignment(left, index, value object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+
+		idx, ok := resolveIndex(integer.Value, len(left.Elements))
+		if !ok {
+			return newError("index out of range: %d", integer.Value)
+		}
+
+		left.Elements[idx] = value
+		return value
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: value}
+		return value
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+func evalTernaryExpression(te *ast.TernaryExpression, environment *object.Environment) object.Object {
+	condition := Eval(te.Condition, environment)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(te.Consequence, environment)
+	}
+
+	return Eval(te.Alternative, environment)
+}
+
+func evalBangOperatorExpression(right object.Object) object.Object {
+	return nativeBoolToBooleanObject(!isTruthy(right))
+}
+
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
 
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return checkedIntegerObject(subInt64(0, value))
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
@@ -237,17 +741,36 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return checkedIntegerObject(addInt64(leftVal, rightVal))
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return checkedIntegerObject(subInt64(leftVal, rightVal))
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return checkedIntegerObject(mulInt64(leftVal, rightVal))
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+
+		// This is the only division whose result doesn't fit in an integer.
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return newError("integer overflow")
+		}
+
+		return nativeIntToIntegerObject(leftVal / rightVal)
+	case "**":
+		if rightVal < 0 {
+			return newError("negative exponent: %d", rightVal)
+		}
+
+		return checkedIntegerObject(integerPower(leftVal, rightVal))
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -257,6 +780,91 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+// integerPower raises base to the power of a non-negative exponent using exponentiation by squaring. The second return
+// value is false if the result overflows.
+func integerPower(base, exponent int64) (int64, bool) {
+	result := int64(1)
+	ok := true
+
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			if result, ok = mulInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+
+		exponent >>= 1
+
+		// Squaring the base after the last step isn't needed, and could overflow even though the result doesn't.
+		if exponent > 0 {
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+
+	return result, true
+}
+
+// checkedIntegerObject returns an integer object for the result of a checked operation, or an error if it overflowed.
+func checkedIntegerObject(value int64, ok bool) object.Object {
+	if !ok {
+		return newError("integer overflow")
+	}
+
+	return nativeIntToIntegerObject(value)
+}
+
+// addInt64 adds two integers. The second return value is false if the result overflows.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, false
+	}
+
+	return c, true
+}
+
+// subInt64 subtracts b from a. The second return value is false if the result overflows.
+func subInt64(a, b int64) (int64, bool) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return 0, false
+	}
+
+	return c, true
+}
+
+// mulInt64 multiplies two integers. The second return value is false if the result overflows.
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	return c, true
+}
+
+// repeatString implements multiplying a string by an integer, which repeats the string that many times. A count of zero
+// or less gives the empty string. The result can't be longer than MaxRepeatLength bytes.
+func repeatString(str *object.String, count *object.Integer) object.Object {
+	if count.Value <= 0 || len(str.Value) == 0 {
+		return &object.String{Value: ""}
+	}
+
+	if count.Value > MaxRepeatLength/int64(len(str.Value)) {
+		return newError("repeated string would be longer than %d bytes", MaxRepeatLength)
+	}
+
+	return &object.String{Value: strings.Repeat(str.Value, int(count.Value))}
+}
+
+// evalStringInfixExpression evaluates an infix expression between two strings. Strings are compared byte-wise, so the
+// comparison is case sensitive ("B" < "a") and the empty string is less than every other string.
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	switch operator {
 	case "+":
@@ -277,6 +885,20 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 
+	case "<", ">", "<=", ">=":
+		comparison := strings.Compare(left.(*object.String).Value, right.(*object.String).Value)
+
+		switch operator {
+		case "<":
+			return nativeBoolToBooleanObject(comparison < 0)
+		case ">":
+			return nativeBoolToBooleanObject(comparison > 0)
+		case "<=":
+			return nativeBoolToBooleanObject(comparison <= 0)
+		default:
+			return nativeBoolToBooleanObject(comparison >= 0)
+		}
+
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
 	}
@@ -288,7 +910,7 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return val
 	}
 
-	if builtin, ok := builtins[node.Value]; ok {
+	if builtin, ok := lookupBuiltin(node.Value); ok {
 		return builtin
 	}
 
@@ -308,29 +930,137 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	}
 }
 
+// evalArrayIndexExpression gets an element from an array. Negative indexes count back from the end of the array, so -1
+// is the last element. NULL is returned if the index is out of range.
 func evalArrayIndexExpression(left, index object.Object) object.Object {
 	array := left.(*object.Array)
-	idx := index.(*object.Integer)
-	max := int64(len(array.Elements) - 1)
 
-	if idx.Value < 0 || idx.Value > max {
+	idx, ok := resolveIndex(index.(*object.Integer).Value, len(array.Elements))
+	if !ok {
 		return NULL
 	}
 
-	return array.Elements[idx.Value]
+	return array.Elements[idx]
 }
 
+// evalStringIndexExpression gets a single character from a string. Like arrays, negative indexes count back from the end
+// of the string and NULL is returned if the index is out of range.
 func evalStringIndexExpression(left, index object.Object) object.Object {
 	str := left.(*object.String)
-	idx := index.(*object.Integer)
-
-	max := int64(len(str.Value) - 1)
 
-	if idx.Value < 0 || idx.Value > max {
+	idx, ok := resolveIndex(index.(*object.Integer).Value, len(str.Value))
+	if !ok {
 		return NULL
 	}
 
-	return &object.String{Value: string(str.Value[idx.Value])}
+	return &object.String{Value: string(str.Value[idx])}
+}
+
+// resolveIndex converts a possibly negative index into an index from the start of a sequence with the given length.
+// The second return value is false if the index is out of range.
+func resolveIndex(idx int64, length int) (int64, bool) {
+	if idx < 0 {
+		idx += int64(length)
+	}
+
+	if idx < 0 || idx >= int64(length) {
+		return 0, false
+	}
+
+	return idx, true
+}
+
+// evalSliceExpression evaluates a slice of an array or a string. Slices are half-open, so arr[1:3] contains the elements
+// at index 1 and 2. An omitted low bound means the start and an omitted high bound means the end. Negative bounds count
+// back from the end, so arr[-2:] is the last two elements. Bounds are clamped to the length of the array or string, and
+// a low bound past the high bound gives an empty result.
+func evalSliceExpression(node *ast.SliceExpression, environment *object.Environment) object.Object {
+	left := Eval(node.Left, environment)
+	if isError(left) {
+		return left
+	}
+
+	var low, high object.Object
+	if node.Low != nil {
+		low = Eval(node.Low, environment)
+		if isError(low) {
+			return low
+		}
+	}
+
+	if node.High != nil {
+		high = Eval(node.High, environment)
+		if isError(high) {
+			return high
+		}
+	}
+
+	return sliceObject(left, low, high)
+}
+
+// sliceObject slices an array or a string between the low and high bounds, following the rules for slice expressions.
+// A nil bound is treated as omitted. It is shared by slice expressions and the `slice` builtin.
+func sliceObject(left, lowBound, highBound object.Object) object.Object {
+	var length int64
+	switch left := left.(type) {
+	case *object.Array:
+		length = int64(len(left.Elements))
+	case *object.String:
+		length = int64(len(left.Value))
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+
+	low, err := sliceBound(lowBound, 0, length)
+	if err != nil {
+		return err
+	}
+
+	high, err := sliceBound(highBound, length, length)
+	if err != nil {
+		return err
+	}
+
+	if low > high {
+		low = high
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		elements := make([]object.Object, high-low)
+		copy(elements, left.Elements[low:high])
+
+		return &object.Array{Elements: elements}
+	default:
+		return &object.String{Value: left.(*object.String).Value[low:high]}
+	}
+}
+
+// sliceBound converts one of the bounds of a slice into an index, returning def if the bound was omitted. The result is
+// always between 0 and length.
+func sliceBound(bound object.Object, def, length int64) (int64, object.Object) {
+	if bound == nil {
+		return def, nil
+	}
+
+	integer, ok := bound.(*object.Integer)
+	if !ok {
+		return 0, newError("slice bound must be INTEGER, got %s", bound.Type())
+	}
+
+	value := integer.Value
+	if value < 0 {
+		value += length
+	}
+
+	switch {
+	case value < 0:
+		return 0, nil
+	case value > length:
+		return length, nil
+	default:
+		return value, nil
+	}
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
@@ -364,7 +1094,7 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 
 	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("unusable as hask key: %s", index.Type())
+		return newError("unusable as hash key: %s", index.Type())
 	}
 
 	pair, ok := hashObject.Pairs[key.HashKey()]
@@ -375,16 +1105,45 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func applyFunction(fn object.Object, args []object.Object, environment *object.Environment) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
+		if cancelled(environment) {
+			return newError("evaluation cancelled")
+		}
+
+		state := stateOf(environment)
+		if state.depth >= MaxCallDepth {
+			return newError("maximum call depth exceeded")
+		}
+
+		state.depth++
+		defer func() { state.depth-- }()
+
+		extendedEnv, err := extendedFunctionEnv(fn, args, environment)
+		if err != nil {
+			return err
+		}
 
-		extendedEnv := extendedFunctionEnv(fn, args)
 		evaluated := Eval(fn.Body, extendedEnv)
 
-		return unwrapReturnVal(evaluated)
+		switch evaluated {
+		case BREAK:
+			return newError("break outside of loop")
+		case CONTINUE:
+			return newError("continue outside of loop")
+		}
+
+		result := unwrapReturnVal(evaluated)
+
+		// An error made by the `error` builtin is only a value inside the function, and stops evaluation once returned.
+		if err, ok := result.(*object.Error); ok && err.Constructed {
+			return &object.Error{Message: err.Message}
+		}
+
+		return result
 	case *object.Builtin:
-		return fn.Fn(args...)
+		return fn.Call(environment, args...)
 
 	default:
 		return newError("not a function: %s", fn.Type())
@@ -392,15 +1151,35 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 
 }
 
-func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewExtendedEnvironment(fn.Env)
+// extendedFunctionEnv creates the environment a function's body is evaluated in, binding each parameter to its argument.
+// Parameters without an argument are bound to their default value, which is evaluated in the new environment so that it
+// can refer to earlier parameters. If a parameter has neither an argument nor a default value, or evaluating a default
+// value fails, the error is returned instead. The function is evaluated as part of the caller's evaluation, even if it
+// was defined during an earlier one.
+func extendedFunctionEnv(
+	fn *object.Function, args []object.Object, caller *object.Environment,
+) (*object.Environment, object.Object) {
+	env := object.NewExtendedEnvironment(fn.Env).WithEvalState(caller.EvalState())
 
 	for paramID, param := range fn.Parameters {
-		env.Set(param.Value, args[paramID])
-	}
+		if paramID < len(args) {
+			env.Set(param.Value, args[paramID])
+			continue
+		}
 
-	return env
+		if paramID >= len(fn.Defaults) || fn.Defaults[paramID] == nil {
+			return nil, newError("missing argument for parameter: %s", param.Value)
+		}
+
+		value := Eval(fn.Defaults[paramID], env)
+		if isError(value) {
+			return nil, value
+		}
+
+		env.Set(param.Value, value)
+	}
 
+	return env, nil
 }
 
 func unwrapReturnVal(obj object.Object) object.Object {
@@ -418,13 +1197,41 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
+// nativeIntToIntegerObject returns an integer object for the given value, reusing a cached object for small integers.
+// Since cached objects are shared, integer objects must never be mutated.
+func nativeIntToIntegerObject(value int64) *object.Integer {
+	if value >= minCachedInteger && value <= maxCachedInteger {
+		return integerCache[value-minCachedInteger]
+	}
+
+	return &object.Integer{Value: value}
+}
+
+// isTruthy reports whether an object counts as true in a condition. Only false and null are not truthy. Booleans and
+// nulls are checked by value rather than against TRUE, FALSE and NULL, since objects converted with object.FromGo are not
+// the same objects.
 func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NULL, FALSE:
+	switch obj := obj.(type) {
+	case *object.Null:
 		return false
+	case *object.Boolean:
+		return obj.Value
 	default:
 		return true
 	}
 }
 
-func isError(obj object.Object) bool { return obj.Type() == object.ERROR_OBJ }
+// isError returns true if the object should stop evaluation and be passed straight back up, which is the case for errors
+// and for calls to `exit`. A nil object is not an error, and neither is an error made by the `error` builtin, since that
+// is a value until it is returned from a function.
+func isError(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+
+	if err, ok := obj.(*object.Error); ok {
+		return !err.Constructed
+	}
+
+	return obj.Type() == object.ERROR_OBJ || obj.Type() == object.EXIT_OBJ
+}
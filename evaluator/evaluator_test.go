@@ -1,8 +1,15 @@
 package evaluator
 
 import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ollybritton/monkey/ast"
 	"github.com/ollybritton/monkey/lexer"
 	"github.com/ollybritton/monkey/object"
 	"github.com/ollybritton/monkey/parser"
@@ -28,6 +35,12 @@ func TestIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"(2 ** 3) ** 2", 64},
+		{"5 ** 0", 1},
+		{"-3 ** 3", -27},
+		{"2 * 3 ** 2", 18},
 	}
 
 	for _, tt := range tests {
@@ -36,6 +49,36 @@ func TestIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestSmallIntegersAreCached(t *testing.T) {
+	first := testEval("5")
+	second := testEval("2 + 3")
+
+	if first != second {
+		t.Errorf("small integers are not shared. got=%p and %p", first, second)
+	}
+
+	large := testEval("1000")
+	if large == testEval("1000") {
+		t.Errorf("large integers should not be cached")
+	}
+
+	testIntegerObject(t, testEval("-128"), -128)
+	testIntegerObject(t, testEval("255 + 1"), 256)
+}
+
+func BenchmarkIntegerArithmetic(b *testing.B) {
+	input := `
+let sum = fn(n, acc) { if (n == 0) { acc } else { sum(n - 1, acc + 1) } };
+sum(100, 0);`
+
+	program := parser.New(lexer.New(input)).ParseProgram()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Eval(program, object.NewEnvironment())
+	}
+}
+
 func TestStringLiteral(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -80,6 +123,68 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestLetStatementValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 5;", 5},
+		{"let a = 5; let b = a * 2;", 10},
+		{"const c = 3;", 3},
+		{"let [a, b] = [1, 2];", []int64{1, 2}},
+		{"let f = fn() { let x = 7; }; f()", 7},
+		{"let f = fn() { let x = 7; }; [f(), f()]", []int64{7, 7}},
+		{"if (true) { let x = 1; }", 1},
+		{"let x = if (true) {}; x", nil},
+		{"let f = fn() {}; f()", nil},
+		{"let x = missing;", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+// unsupportedNode is an expression which the evaluator doesn't know how to evaluate. The embedded interface is never set,
+// it only gives the type the unexported method needed to be used as an ast.Expression.
+type unsupportedNode struct {
+	ast.Expression
+}
+
+func (n *unsupportedNode) TokenLiteral() string { return "" }
+func (n *unsupportedNode) String() string       { return "" }
+
+func TestUnknownNodeType(t *testing.T) {
+	node := &unsupportedNode{}
+	testErrorObject(t, Eval(node, object.NewEnvironment()), "unknown node type: *evaluator.unsupportedNode")
+
+	// The error is passed up like any other, rather than the nil result causing a panic further up.
+	program := &ast.Program{Statements: []ast.Statement{
+		&ast.ExpressionStatement{Expression: &ast.InfixExpression{
+			Left:     node,
+			Operator: "+",
+			Right:    &ast.IntegerLiteral{Value: 1},
+		}},
+	}}
+
+	testErrorObject(t, Eval(program, object.NewEnvironment()), "unknown node type: *evaluator.unsupportedNode")
+
+	if isError(nil) {
+		t.Errorf("isError(nil) returned true")
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -91,6 +196,10 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 > 2", false},
 		{"1 < 1", false},
 		{"1 > 1", false},
+		{"1 <= 1", true},
+		{"1 >= 1", true},
+		{"1 <= 0", false},
+		{"2 >= 3", false},
 		{"1 == 1", true},
 		{"1 != 1", false},
 		{"1 == 2", false},
@@ -104,6 +213,17 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"[1, 2, 3] == [1, 2, 3]", true},
+		{"[1, 2, 3] != [1, 2, 3]", false},
+		{"[1, 2] == [1, 2, 3]", false},
+		{`[1, "a", [true]] == [1, "a", [true]]`, true},
+		{"let a = [1]; let b = [2]; a == b", false},
+		{"[] == []", true},
+		{"[[1, [2]], 3] == [[1, [2]], 3]", true},
+		{"[[1, [2]], 3] == [[1, [4]], 3]", false},
+		{"[[1, 2]] == [[1, 2, 3]]", false},
+		{"[1, 2] != [1]", true},
+		{"let a = [1, [2]]; let b = [1, [2]]; a == b", true},
 	}
 
 	for _, tt := range tests {
@@ -112,6 +232,88 @@ func TestEvalBooleanExpression(t *testing.T) {
 	}
 }
 
+func TestEqualityOfFreshObjects(t *testing.T) {
+	tests := []struct {
+		operator string
+		left     object.Object
+		right    object.Object
+		expected bool
+	}{
+		{"==", &object.Integer{Value: 5}, &object.Integer{Value: 5}, true},
+		{"!=", &object.Integer{Value: 5}, &object.Integer{Value: 5}, false},
+		{"==", &object.Boolean{Value: true}, &object.Boolean{Value: true}, true},
+		{"==", &object.Null{}, NULL, true},
+		{
+			"==",
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+			&object.Array{Elements: []object.Object{&object.Integer{Value: 1}}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, evalInfixExpression(tt.operator, tt.left, tt.right), tt.expected)
+	}
+}
+
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let [a, b, c] = [1, 2, 3]; a * 100 + b * 10 + c", 123},
+		{"let pair = fn() { [4, 5] }; let [x, y] = pair(); x - y", -1},
+		{"let [a, b] = [[1, 2], 3]; a[1] + b", 5},
+		{"let a = 1; if (true) { let [a] = [2]; a }", 2},
+		{"const [k] = [1]; k = 2", object.Error{Message: "cannot assign to constant: k"}},
+		{"const k = 1; let [k] = [2]", object.Error{Message: "cannot assign to constant: k"}},
+		{"let [a, b] = [1]", object.Error{Message: "wrong number of values to destructure. got=1, want=2"}},
+		{"let [a, b] = [1, 2, 3]", object.Error{Message: "wrong number of values to destructure. got=3, want=2"}},
+		{`let [a] = "a"`, object.Error{Message: "cannot destructure STRING, want ARRAY"}},
+		{"let [a] = [missing]", object.Error{Message: "identifier not found: missing"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestNullLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"null", nil},
+		{"let x = null; x", nil},
+		{"null == null", true},
+		{"null != null", false},
+		{"!null", true},
+		{"if (null) { 1 } else { 2 }", 2},
+		{"let x = null; x = 5; x", 5},
+		{`let h = {"a": 1}; h["b"] == null`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestBangOperator(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -156,6 +358,285 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
+func TestSwitchExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"switch (2) { case 1: 10 case 2: 20 case 3: 30 }", 20},
+		{"switch (4) { case 1: 10 default: 40 }", 40},
+		{"switch (4) { default: 40 case 4: 10 }", 10},
+		{"switch (4) { case 1: 10 case 2: 20 }", nil},
+		{"switch (1) { case 1: case 2: 20 }", nil},
+		{"switch (1) { case 1: 10; 11 case 1: 20 }", 11},
+		{`switch ("b") { case "a": 1 case "b": 2 }`, 2},
+		{`switch (1) { case "1": 1 default: 2 }`, 2},
+		{"switch ([1, 2]) { case [1, 2]: 1 }", 1},
+		{"let x = 3; switch (x * 2) { case x + 1: 1 case x + x: 2 }", 2},
+		{"let f = fn(x) { switch (x) { case 1: return 10; } 20 }; f(1) + f(2)", 30},
+		{"switch (3) { case 1, 2, 3: 10 default: 20 }", 10},
+		{"switch (4) { case 1, 2, 3: 10 default: 20 }", 20},
+		{"switch (4) { case 1, 2, 3: 10 }", nil},
+		{`switch ("b") { case "a", "b": 1 case "b": 2 }`, 1},
+		{"switch (1) { case 1, missing: 1 }", 1},
+		{"switch (2) { case 1, missing: 1 }", "identifier not found: missing"},
+		{"switch (missing) { case 1: 1 }", "identifier not found: missing"},
+		{"switch (1) { case 2: 1 case missing: 2 }", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"try { 1 / 0 } catch (e) { e }", "division by zero"},
+		{"try { 10 / 2 } catch (e) { 0 }", 5},
+		{"try { missing } catch (e) { len(e) }", 29},
+		{"try { let a = 1; a + true; 2 } catch (e) { -1 }", -1},
+		{"try { } catch (e) { 1 }", nil},
+		{"let f = fn() { try { return 1; } catch (e) { 2 }; 3 }; f()", 1},
+		{"let f = fn(x) { 100 / x }; try { f(0) } catch (err) { err + \"!\" }", "division by zero!"},
+		{"try { try { 1 / 0 } catch (e) { e + true } } catch (e) { e }", "type mismatch: STRING + BOOLEAN"},
+		{"try { 1 / 0 } catch (e) { 1 }; e", object.Error{Message: "identifier not found: e"}},
+		{"try { 1 / 0 } catch (e) { missing }", object.Error{Message: "identifier not found: missing"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestErrorBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`is_error(error("boom"))`, true},
+		{`let e = error("boom"); is_error(e)`, true},
+		{`is_error("boom")`, false},
+		{`is_error(null)`, false},
+		{`let e = error("boom"); 5`, 5},
+		{`let errs = [error("a"), 1]; is_error(errs[0]) && !is_error(errs[1])`, true},
+		{`if (true) { error("boom"); 5 }`, 5},
+		{`error("boom")`, object.Error{Message: "boom"}},
+		{`let f = fn() { error("bad") }; f(); 5`, object.Error{Message: "bad"}},
+		{`let f = fn() { return error("bad"); }; is_error(f())`, object.Error{Message: "bad"}},
+		{`let f = fn() { error("bad") }; try { f() } catch (e) { e }`, "bad"},
+		{`let f = fn(x) { if (x < 0) { return error("negative") } x }; f(2) + f(3)`, 5},
+		{`is_error(try { 1 / 0 } catch (e) { error(e) })`, true},
+		{`error(1)`, object.Error{Message: "argument to `error` must be STRING, got INTEGER"}},
+		{`is_error()`, object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+
+	// A constructed error only stops evaluation once it is returned from a function.
+	if err, ok := testEval(`error("boom")`).(*object.Error); !ok || !err.Constructed {
+		t.Errorf("error builtin did not return a constructed error. got=%+v", err)
+	}
+
+	if err, ok := testEval(`let f = fn() { error("bad") }; f()`).(*object.Error); !ok || err.Constructed {
+		t.Errorf("error returned from function is still constructed. got=%+v", err)
+	}
+}
+
+func TestTernaryExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"true ? 10 : 20", 10},
+		{"false ? 10 : 20", 20},
+		{"1 < 2 ? 10 : 20", 10},
+		{"1 > 2 ? 10 : 20", 20},
+		{"0 ? 10 : 20", 10},
+		{"1 > 2 ? 10 : 2 > 3 ? 20 : 30", 30},
+		{"true ? false ? 1 : 2 : 3", 2},
+		{"let max = fn(a, b) { a > b ? a : b }; max(3, 7)", 7},
+		{"if (1 > 2) { 1 } ? 10 : 20", 20},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for (let i = 0; i < 10; i = i + 1) { sum = sum + i; }; sum", 45},
+		{"let sum = 0; let i = 0; for (; i < 10;) { sum = sum + i; i = i + 1; }; sum", 45},
+		{"let sum = 0; for (let i = 0; i < 0; i = i + 1) { sum = sum + 1; }; sum", 0},
+		{"let i = 100; for (let i = 0; i < 3; i = i + 1) {}; i", 100},
+		{"let f = fn() { for (let i = 0; i < 10; i = i + 1) { if (i == 3) { return i; } } }; f()", 3},
+		{"for (let i = 0; i < 10; i = i + 1) {}", nil},
+		{"for (let i = 0; i < 10; i = i + 1) { i + true }", "type mismatch: INTEGER + BOOLEAN"},
+		{"for (let i = 0; i + true; i = i + 1) {}", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDoWhileStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; do { i = i + 1; } while (i < 5); i", 5},
+		{"let runs = 0; do { runs = runs + 1; } while (false); runs", 1},
+		{"let i = 0; do { i = i + 1; if (i == 3) { break; } } while (true); i", 3},
+		{"let i = 0; let odd = 0; do { i = i + 1; if (i / 2 * 2 == i) { continue; } odd = odd + 1; } while (i < 10); odd", 5},
+		{"let f = fn() { do { return 7; } while (true); }; f()", 7},
+		{"do {} while (false)", nil},
+		{"do { 1 + true } while (false)", "type mismatch: INTEGER + BOOLEAN"},
+		{"do {} while (1 + true)", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBreakAndContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; for (;;) { if (i == 5) { break; } i = i + 1; }; i", 5},
+		{"let sum = 0; for (let i = 0; i < 10; i = i + 1) { if (i == 3) { break } sum = sum + i; }; sum", 3},
+		{"let sum = 0; for (let i = 0; i < 10; i = i + 1) { if (i / 2 * 2 == i) { continue } sum = sum + i; }; sum", 25},
+		{`let n = 0;
+		  for (let i = 0; i < 3; i = i + 1) {
+		    for (let j = 0; j < 3; j = j + 1) {
+		      if (j == 1) { break; }
+		      n = n + 1;
+		    }
+		  };
+		  n`, 3},
+		{"break", "break outside of loop"},
+		{"continue", "continue outside of loop"},
+		{"if (true) { break }", "break outside of loop"},
+		{"let f = fn() { break }; for (;;) { f() }", "break outside of loop"},
+		{"let f = fn() { continue }; f()", "continue outside of loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestForInStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for x in [1, 2, 3] { sum = sum + x; }; sum", 6},
+		{"let sum = 0; for i, x in [10, 20, 30] { sum = sum + i * x; }; sum", 80},
+		{"let sum = 0; for x in [] { sum = sum + 1; }; sum", 0},
+		{`let s = ""; for c in "abc" { s = c + s; }; s`, "cba"},
+		{`let s = ""; for i, c in "abc" { if (i == 1) { s = s + c } }; s`, "b"},
+		{`let s = ""; for c in "café" { s = c + s; }; s`, "éfac"},
+		{`let s = ""; for i, c in "café!" { if (i == 3) { s = s + c } }; s`, "é"},
+		{`let s = ""; for i, c in "café!" { if (c == "!") { s = "café!"[i] } }; s`, "!"},
+		{`let s = ""; for i, c in "naïve" { if (c == "v") { s = "naïve"[i:] } }; s`, "ve"},
+		{`let n = 0; for i, c in "café!" { if (c == "!") { n = i } }; n`, 5},
+		{`index_of("café!", "!")`, 5},
+		{`let n = 0; for c in "café" { n = n + 1; }; n`, 4},
+		{`let s = ""; for k in {"b": 2, "a": 1} { s = s + k; }; s`, "ab"},
+		{`let sum = 0; for k, v in {"a": 1, "b": 2} { sum = sum + v; }; sum`, 3},
+		{"let sum = 0; for x in [1, 2, 3, 4] { if (x == 3) { break } sum = sum + x; }; sum", 3},
+		{"let sum = 0; for x in [1, 2, 3, 4] { if (x == 3) { continue } sum = sum + x; }; sum", 7},
+		{"let x = 100; for x in [1, 2, 3] {}; x", 100},
+		{"let f = fn() { for x in [1, 2, 3] { if (x == 2) { return x } } }; f()", 2},
+		{"let fs = []; for x in [1, 2] { fs = push(fs, fn() { x }) }; fs[0]() + fs[1]()", 3},
+		{"for x in 5 {}", "cannot iterate over INTEGER"},
+		{"for x in [1] { x + true }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if _, ok := evaluated.(*object.Error); ok {
+				testErrorObject(t, evaluated, expected)
+			} else {
+				testStringObject(t, evaluated, expected)
+			}
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -228,6 +709,34 @@ if (10 > 1) {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			"2 ** -1",
+			"negative exponent: -1",
+		},
+		{
+			"[1, 2][true:]",
+			"slice bound must be INTEGER, got BOOLEAN",
+		},
+		{
+			"5[1:2]",
+			"slice operator not supported: INTEGER",
+		},
+		{
+			"10 / (5 - 5)",
+			"division by zero",
+		},
+		{
+			`{fn(x) { x }: "Monkey"}`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			`{"a": 1, [1, 2]: "Monkey"}`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			`{{}: 1}`,
+			"unusable as hash key: HASH",
+		},
 	}
 
 	for _, tt := range tests {
@@ -287,48 +796,1264 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
-func TestClosures(t *testing.T) {
-	input := `
-let newAdder = fn(x) {
-	fn(y) { x + y };
-};
-let addTwo = newAdder(2);
-addTwo(2);`
-
-	testIntegerObject(t, testEval(input), 4)
-}
-
-func TestBuiltinFunctions(t *testing.T) {
+func TestDefaultParameters(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected interface{}
 	}{
-		{`len("")`, 0},
-		{`len("four")`, 4},
+		{"let add = fn(x, y = 10) { x + y }; add(1)", 11},
+		{"let add = fn(x, y = 10) { x + y }; add(1, 2)", 3},
+		{"let f = fn(x = 1, y = x + 1) { x * y }; f()", 2},
+		{"let f = fn(x = 1, y = x + 1) { x * y }; f(3)", 12},
+		{"let n = 5; let f = fn(x = n) { x }; let n = 6; f()", 6},
+		{"let f = fn(x = 1, y) { x + y }; f(2, 3)", 5},
+		{"let f = fn(x = 1, y) { x + y }; f(2)", "missing argument for parameter: y"},
+		{"let f = fn(x, y) { x + y }; f(1)", "missing argument for parameter: y"},
+		{"let f = fn(x = 1 + true) { x }; f()", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestClosures(t *testing.T) {
+	input := `
+let newAdder = fn(x) {
+	fn(y) { x + y };
+};
+let addTwo = newAdder(2);
+addTwo(2);`
+
+	testIntegerObject(t, testEval(input), 4)
+}
+
+func TestMaximumCallDepth(t *testing.T) {
+	input := `let f = fn(x) { f(x + 1) }; f(0);`
+	testErrorObject(t, testEval(input), "maximum call depth exceeded")
+
+	// The depth should be reset once the error has been returned.
+	input = `let countdown = fn(x) { if (x == 0) { 0 } else { countdown(x - 1) } }; countdown(100);`
+	testIntegerObject(t, testEval(input), 0)
+}
+
+func TestMaximumCallDepthIsConfigurable(t *testing.T) {
+	defer func(depth int) { MaxCallDepth = depth }(MaxCallDepth)
+	MaxCallDepth = 10
+
+	input := `let countdown = fn(x) { if (x == 0) { 0 } else { countdown(x - 1) } };`
+	testIntegerObject(t, testEval(input+"countdown(9);"), 0)
+	testErrorObject(t, testEval(input+"countdown(10);"), "maximum call depth exceeded")
+}
+
+func TestMaximumCallDepthConcurrent(t *testing.T) {
+	defer func(depth int) { MaxCallDepth = depth }(MaxCallDepth)
+	MaxCallDepth = 50
+
+	// Each evaluation has its own depth, so concurrent ones close to the limit don't push each other over it.
+	input := `let countdown = fn(x) { if (x == 0) { 0 } else { countdown(x - 1) } }; countdown(45);`
+
+	var wg sync.WaitGroup
+	results := make([]object.Object, 4)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20 && results[i] == nil; j++ {
+				if evaluated := testEval(input); isError(evaluated) {
+					results[i] = evaluated
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, result := range results {
+		if result != nil {
+			t.Errorf("evaluation %d failed: %s", i, result.Inspect())
+		}
+	}
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
 		{`len("hello world")`, 11},
-		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len([1, [2, 3]])`, 2},
+		{`len({})`, 0},
+		{`len({"a": 1})`, 1},
+		{`len({"a": 1, "b": [1, 2, 3]})`, 2},
+		{`len(1)`, "argument to `len` has no length, got INTEGER"},
+		{`len(5)`, "argument to `len` has no length, got INTEGER"},
+		{`len(true)`, "argument to `len` has no length, got BOOLEAN"},
+		{`len(fn(x) { x })`, "argument to `len` not supported, got FUNCTION"},
+		{`abs(5)`, 5},
+		{`abs(-5)`, 5},
+		{`abs(0)`, 0},
+		{`abs(-9223372036854775807)`, 9223372036854775807},
+		{`abs(-9223372036854775807 - 1)`, "integer overflow"},
+		{`abs("a")`, "argument to `abs` not supported, got STRING"},
+		{`min(3)`, 3},
+		{`min(3, -1, 2)`, -1},
+		{`min()`, "wrong number of arguments. got=0, want>=1"},
+		{`min(1, "a")`, "argument to `min` not supported, got STRING"},
+		{`max(3)`, 3},
+		{`max(3, -1, 7, 2)`, 7},
+		{`max(-3, -1)`, -1},
+		{`max()`, "wrong number of arguments. got=0, want>=1"},
+		{`max(true)`, "argument to `max` not supported, got BOOLEAN"},
+		{`first([4, 5])`, 4},
+		{`first([])`, nil},
+		{`first("")`, nil},
+		{`first(1)`, "argument to `first` not supported, got INTEGER"},
+		{`last([4, 5])`, 5},
+		{`last([])`, nil},
+		{`last("")`, nil},
+		{`last(1)`, "argument to `last` not supported, got INTEGER"},
+		{`assert(true)`, nil},
+		{`assert(1 < 2, "maths is broken")`, nil},
+		{`assert(false)`, "assertion failed"},
+		{`assert(1 > 2, "maths is broken")`, "assertion failed: maths is broken"},
+		{`assert(false, 1)`, "message passed to `assert` must be STRING, got INTEGER"},
+		{`assert()`, "wrong number of arguments. got=0, want=1 or 2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+			}
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestSortBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"sort([3, 1, 2])", []int64{1, 2, 3}},
+		{"sort([])", []int64{}},
+		{"sort([5, -1, 5, 0])", []int64{-1, 0, 5, 5}},
+		{`sort(["banana", "apple", "cherry"])`, []string{"apple", "banana", "cherry"}},
+		{"sort([1, 3, 2], fn(a, b) { a > b })", []int64{3, 2, 1}},
+		{"let a = [3, 1, 2]; sort(a); a", []int64{3, 1, 2}},
+		{`sort([1, "a"])`, "cannot sort array with mixed types: INTEGER and STRING"},
+		{"sort(1)", "argument to `sort` must be ARRAY, got INTEGER"},
+		{"sort([true, false])", "cannot sort array of BOOLEAN without a comparator"},
+		{"sort([1, 2], 3)", "comparator passed to `sort` must be FUNCTION, got INTEGER"},
+		{"sort([1, 2], fn(a, b) { a + true })", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case []string:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, e := range expected {
+				str, ok := array.Elements[i].(*object.String)
+				if !ok || str.Value != e {
+					t.Errorf("element %d wrong. want=%q, got=%+v", i, e, array.Elements[i])
+				}
+			}
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestReverseBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"reverse([1, 2, 3])", []int64{3, 2, 1}},
+		{"reverse([])", []int64{}},
+		{"let a = [1, 2, 3]; reverse(a); a", []int64{1, 2, 3}},
+		{`reverse("hello")`, "olleh"},
+		{`reverse("")`, ""},
+		{`reverse("café")`, "éfac"},
+		{`reverse("naïve")`, "evïan"},
+		{"reverse(1)", object.Error{Message: "argument to `reverse` not supported, got INTEGER"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1", "integer overflow"},
+		{"9223372036854775806 + 1", 9223372036854775807},
+		{"-9223372036854775807 + -2", "integer overflow"},
+		{"-9223372036854775807 - 2", "integer overflow"},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+		{"9223372036854775807 - -1", "integer overflow"},
+		{"4611686018427387904 * 2", "integer overflow"},
+		{"4611686018427387904 * -2", -9223372036854775808},
+		{"-4611686018427387904 * -2", "integer overflow"},
+		{"(-9223372036854775807 - 1) * -1", "integer overflow"},
+		{"-1 * (-9223372036854775807 - 1)", "integer overflow"},
+		{"(-9223372036854775807 - 1) / -1", "integer overflow"},
+		{"(-9223372036854775807 - 1) / 1", -9223372036854775808},
+		{"-9223372036854775807 / -1", 9223372036854775807},
+		{"-(-9223372036854775807 - 1)", "integer overflow"},
+		{"let x = -9223372036854775807 - 1; -x", "integer overflow"},
+		{"let x = -9223372036854775807; -x", 9223372036854775807},
+		{"3037000499 * 3037000499", 9223372030926249001},
+		{"3037000500 * 3037000500", "integer overflow"},
+		{"2 ** 62", 4611686018427387904},
+		{"2 ** 63", "integer overflow"},
+		{"-2 ** 63", -9223372036854775808},
+		{"3 ** 40", "integer overflow"},
+		{"1 ** 1000000", 1},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestStringMultiplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"ab" * 3`, "ababab"},
+		{`3 * "ab"`, "ababab"},
+		{`"ab" * 1`, "ab"},
+		{`"ab" * 0`, ""},
+		{`"ab" * -2`, ""},
+		{`"" * 5`, ""},
+		{`"-" * 2 + "|"`, "--|"},
+		{`"ab" / 2`, object.Error{Message: "type mismatch: STRING / INTEGER"}},
+		{`"ab" * "cd"`, object.Error{Message: "unknown operator: STRING * STRING"}},
+		{`"ab" * 9223372036854775807`, object.Error{Message: "repeated string would be longer than 16777216 bytes"}},
+		{`"ab" * 8388609`, object.Error{Message: "repeated string would be longer than 16777216 bytes"}},
+		{`len("ab" * 8388608)`, 16777216},
+		{`"" * 9223372036854775807`, ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"abc" < "abd"`, true},
+		{`"abd" < "abc"`, false},
+		{`"" < "a"`, true},
+		{`"a" > ""`, true},
+		{`"ab" < "abc"`, true},
+		{`"B" < "a"`, true},
+		{`"a" <= "a"`, true},
+		{`"a" >= "a"`, true},
+		{`"a" <= "A"`, false},
+		{`"b" >= "a"`, true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestStringCaseBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`trim("  hello  ")`, "hello"},
+		{`trim("\t\nhello world\n")`, "hello world"},
+		{`trim("   ")`, ""},
+		{`trim("")`, ""},
+		{`upper("Hello, World")`, "HELLO, WORLD"},
+		{`upper("123")`, "123"},
+		{`lower("Hello, World")`, "hello, world"},
+		{`lower(upper("MiXeD"))`, "mixed"},
+		{`trim(1)`, object.Error{Message: "argument to `trim` must be STRING, got INTEGER"}},
+		{`upper([])`, object.Error{Message: "argument to `upper` must be STRING, got ARRAY"}},
+		{`lower(true)`, object.Error{Message: "argument to `lower` must be STRING, got BOOLEAN"}},
+		{`upper("a", "b")`, object.Error{Message: "wrong number of arguments. got=2, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestReplaceBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`replace("hello world", "o", "0")`, "hell0 w0rld"},
+		{`replace("aaaa", "aa", "b")`, "bb"},
+		{`replace("hello", "l", "")`, "heo"},
+		{`replace("hello", "z", "y")`, "hello"},
+		{`replace("", "a", "b")`, ""},
+		{`replace("abc", "", "-")`, object.Error{Message: "string to replace passed to `replace` cannot be empty"}},
+		{`replace("abc", 1, "-")`, object.Error{Message: "argument to `replace` must be STRING, got INTEGER"}},
+		{`replace(1, "a", "-")`, object.Error{Message: "argument to `replace` must be STRING, got INTEGER"}},
+		{`replace("abc", "a")`, object.Error{Message: "wrong number of arguments. got=2, want=3"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestPrefixSuffixBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`starts_with("hello", "he")`, true},
+		{`starts_with("hello", "lo")`, false},
+		{`starts_with("hello", "")`, true},
+		{`starts_with("", "")`, true},
+		{`starts_with("he", "hello")`, false},
+		{`ends_with("hello", "lo")`, true},
+		{`ends_with("hello", "he")`, false},
+		{`ends_with("hello", "")`, true},
+		{`ends_with("hello", "hello")`, true},
+		{`starts_with("hello", 1)`, object.Error{Message: "argument to `starts_with` must be STRING, got INTEGER"}},
+		{`ends_with(["a"], "a")`, object.Error{Message: "argument to `ends_with` must be STRING, got ARRAY"}},
+		{`starts_with("a")`, object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestCharsBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`chars("abc")`, []string{"a", "b", "c"}},
+		{`chars("a b")`, []string{"a", " ", "b"}},
+		{`chars("")`, []string{}},
+		{`chars("héllo")`, []string{"h", "é", "l", "l", "o"}},
+		{`chars("\u03bb!")`, []string{"λ", "!"}},
+		{`chars(1)`, object.Error{Message: "argument to `chars` must be STRING, got INTEGER"}},
+		{`chars()`, object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []string:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("array has wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, e := range expected {
+				testStringObject(t, array.Elements[i], e)
+			}
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestFormatBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`format("{} + {} = {}", 1, 2, 3)`, "1 + 2 = 3"},
+		{`format("no placeholders")`, "no placeholders"},
+		{`format("{}{}", "a", [1, true])`, "a[1, true]"},
+		{`format("hello, {}!", "world")`, "hello, world!"},
+		{`format("{} + {} = {}", 1, 2)`, object.Error{Message: "wrong number of values for format string. got=2, want=3"}},
+		{`format("{}", 1, 2)`, object.Error{Message: "wrong number of values for format string. got=2, want=1"}},
+		{`format(1)`, object.Error{Message: "first argument to `format` must be STRING, got INTEGER"}},
+		{`format()`, object.Error{Message: "wrong number of arguments. got=0, want>=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestCharCodeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`ord("A")`, 65},
+		{`ord("a")`, 97},
+		{`ord(chr(200))`, 200},
+		{`chr(65)`, "A"},
+		{`chr(ord("z"))`, "z"},
+		{`ord("")`, object.Error{Message: "argument to `ord` must be a single character, got \"\""}},
+		{`ord("ab")`, object.Error{Message: "argument to `ord` must be a single character, got \"ab\""}},
+		{`ord(1)`, object.Error{Message: "argument to `ord` not supported, got INTEGER"}},
+		{`chr(256)`, object.Error{Message: "argument to `chr` out of range: 256"}},
+		{`chr(-1)`, object.Error{Message: "argument to `chr` out of range: -1"}},
+		{`chr("A")`, object.Error{Message: "argument to `chr` not supported, got STRING"}},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
 
-		switch expected := tt.expected.(type) {
-		case int:
-			testIntegerObject(t, evaluated, int64(expected))
-		case string:
-			errObj, ok := evaluated.(*object.Error)
-			if !ok {
-				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
-				continue
-			}
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestHashKeysOfDifferentTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let h = {1: "a", "1": "b"}; h[1]`, "a"},
+		{`let h = {1: "a", "1": "b"}; h["1"]`, "b"},
+		{`let h = {1: "a", true: "b"}; h[true]`, "b"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	hash, ok := testEval(`{1: "a", "1": "b", true: "c"}`).(*object.Hash)
+	if !ok {
+		t.Fatalf("object is not Hash")
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Errorf("hash has wrong number of pairs. got=%d, want=3", len(hash.Pairs))
+	}
+}
+
+func TestHasBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`has({1: if (false) { 1 }}, 1)`, true},
+		{`has({true: 1}, false)`, false},
+		{`has({}, 1)`, false},
+		{`has({"a": 1}, [1])`, "unusable as hash key: ARRAY"},
+		{`has({"a": 1}, fn(x) { x })`, "unusable as hash key: FUNCTION"},
+		{`has([1], 1)`, "first argument to `has` must be HASH, got ARRAY"},
+		{`has({})`, "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestDeleteBuiltin(t *testing.T) {
+	arrayTests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"delete([1, 2, 3], 0)", []int64{2, 3}},
+		{"delete([1, 2, 3], 1)", []int64{1, 3}},
+		{"delete([1, 2, 3], -1)", []int64{1, 2}},
+		{"let a = [1, 2, 3]; delete(a, 0); a", []int64{1, 2, 3}},
+		{"delete([1, 2, 3], 3)", "index out of range: 3"},
+		{"delete([], 0)", "index out of range: 0"},
+		{`delete([1], "a")`, "array index must be INTEGER, got STRING"},
+		{"delete(1, 0)", "argument to `delete` not supported, got INTEGER"},
+		{"delete([1])", "wrong number of arguments. got=1, want=2"},
+	}
+
+	for _, tt := range arrayTests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+
+	hashTests := []struct {
+		input        string
+		expectedKeys []string
+	}{
+		{`delete({"a": 1, "b": 2}, "a")`, []string{"b"}},
+		{`delete({"a": 1, "b": 2}, "c")`, []string{"a", "b"}},
+		{`let h = {"a": 1}; delete(h, "a"); h`, []string{"a"}},
+	}
+
+	for _, tt := range hashTests {
+		evaluated := testEval(tt.input)
+
+		hash, ok := evaluated.(*object.Hash)
+		if !ok {
+			t.Errorf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if len(hash.Pairs) != len(tt.expectedKeys) {
+			t.Errorf("hash has wrong number of pairs. got=%d, want=%d", len(hash.Pairs), len(tt.expectedKeys))
+		}
+
+		for _, key := range tt.expectedKeys {
+			if _, ok := hash.Pairs[(&object.String{Value: key}).HashKey()]; !ok {
+				t.Errorf("hash is missing key %q", key)
+			}
+		}
+	}
+
+	testErrorObject(t, testEval(`delete({}, [1])`), "unusable as hash key: ARRAY")
+}
+
+func TestTypePredicateBuiltins(t *testing.T) {
+	values := map[string]string{
+		"int":      "1",
+		"string":   `"a"`,
+		"array":    "[1, 2]",
+		"hash":     `{"a": 1}`,
+		"boolean":  "true",
+		"null":     "null",
+		"function": "fn(x) { x }",
+		"builtin":  "len",
+	}
+
+	tests := []struct {
+		predicate string
+		matches   []string
+	}{
+		{"is_array", []string{"array"}},
+		{"is_string", []string{"string"}},
+		{"is_int", []string{"int"}},
+		{"is_fn", []string{"function", "builtin"}},
+	}
+
+	for _, tt := range tests {
+		for kind, value := range values {
+			expected := false
+			for _, match := range tt.matches {
+				if kind == match {
+					expected = true
+				}
+			}
+
+			evaluated := testEval(tt.predicate + "(" + value + ")")
+			if !testBooleanObject(t, evaluated, expected) {
+				t.Errorf("wrong result for %s(%s)", tt.predicate, value)
+			}
+		}
+
+		testErrorObject(t, testEval(tt.predicate+"()"), "wrong number of arguments. got=0, want=1")
+	}
+}
+
+func TestEnvBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let x = 5; env()["x"]`, 5},
+		{`let x = 5; let y = 6; len(env())`, 2},
+		{`len(env())`, 0},
+		{`let x = 1; let f = fn(x) { env()["x"] }; f(2)`, 2},
+		{`let outer = 1; let f = fn(a) { let b = 3; len(env()) }; f(2)`, 4},
+		{`let x = 5; env().x`, 5},
+		{`let h = env(); h["missing"]`, nil},
+		{`env(1)`, object.Error{Message: "wrong number of arguments. got=1, want=0"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case nil:
+			testNullObject(t, evaluated)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestSumAndProductBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"sum([1, 2, 3, 4])", 10},
+		{"sum([-5, 5])", 0},
+		{"sum([7])", 7},
+		{"sum([])", 0},
+		{"product([1, 2, 3, 4])", 24},
+		{"product([-2, 3])", -6},
+		{"product([])", 1},
+		{"product([5, 0, 9])", 0},
+		{`sum([1, "2"])`, object.Error{Message: "array passed to `sum` must only contain INTEGER, got STRING"}},
+		{"product([2, true])", object.Error{Message: "array passed to `product` must only contain INTEGER, got BOOLEAN"}},
+		{"sum(1)", object.Error{Message: "argument to `sum` must be ARRAY, got INTEGER"}},
+		{"product()", object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+		{"sum([9223372036854775807, 1])", object.Error{Message: "integer overflow"}},
+		{"product([4611686018427387904, 2])", object.Error{Message: "integer overflow"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestParseJSONBuiltin(t *testing.T) {
+	doc := `let doc = parse_json("{\"name\": \"monkey\", \"version\": 2, \"tags\": [\"a\", \"b\"], \"meta\": {\"stable\": true, \"parent\": null}}");`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{doc + `doc["name"]`, "monkey"},
+		{doc + `doc["version"]`, 2},
+		{doc + `doc["tags"][1]`, "b"},
+		{doc + `len(doc["tags"])`, 2},
+		{doc + `doc["meta"]["stable"]`, true},
+		{doc + `doc["meta"]["parent"]`, nil},
+		{doc + `len(doc)`, 4},
+		{`parse_json("[1, -2, [3]]")[2][0]`, 3},
+		{`parse_json("\"\\u00e9\"")`, "é"},
+		{`parse_json("null")`, nil},
+		{`parse_json("{")`, object.Error{Message: "could not parse JSON: unexpected EOF"}},
+		{`parse_json("[1] 2")`, object.Error{Message: "could not parse JSON: unexpected data after top-level value"}},
+		{`parse_json("1.5")`, object.Error{Message: "could not parse JSON: number 1.5 is not an INTEGER"}},
+		{`parse_json(1)`, object.Error{Message: "argument to `parse_json` must be STRING, got INTEGER"}},
+		{`parse_json()`, object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestToJSONBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`to_json(1)`, "1"},
+		{`to_json("a \"quoted\" <string>")`, `"a \"quoted\" <string>"`},
+		{`to_json(true)`, "true"},
+		{`to_json(null)`, "null"},
+		{`to_json([])`, "[]"},
+		{`to_json({})`, "{}"},
+		{`to_json([1, "two", [false, null]])`, `[1,"two",[false,null]]`},
+		{`to_json({"b": {"c": [1, 2]}, "a": 1})`, `{"a":1,"b":{"c":[1,2]}}`},
+		{`let doc = {"name": "monkey", "tags": ["a"]}; to_json(parse_json(to_json(doc)))`, `{"name":"monkey","tags":["a"]}`},
+		{`to_json({1: "one"})`, object.Error{Message: "cannot convert hash key of type INTEGER to JSON, want STRING"}},
+		{`to_json([1, fn(x) { x }])`, object.Error{Message: "cannot convert FUNCTION to JSON"}},
+		{`to_json({"f": len})`, object.Error{Message: "cannot convert BUILTIN to JSON"}},
+		{`to_json()`, object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestParseIntBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`parse_int("FF", 16)`, 255},
+		{`parse_int("ff", 16)`, 255},
+		{`parse_int("101", 2)`, 5},
+		{`parse_int("-42", 10)`, -42},
+		{`parse_int("z", 36)`, 35},
+		{`parse_int("777", 8)`, 511},
+		{`parse_int("102", 2)`, object.Error{Message: "could not parse \"102\" as an integer in base 2"}},
+		{`parse_int("", 10)`, object.Error{Message: "could not parse \"\" as an integer in base 10"}},
+		{`parse_int("10", 1)`, object.Error{Message: "base passed to `parse_int` out of range: 1, want 2 to 36"}},
+		{`parse_int("10", 37)`, object.Error{Message: "base passed to `parse_int` out of range: 37, want 2 to 36"}},
+		{`parse_int("8000000000000000", 16)`, object.Error{Message: "integer overflow"}},
+		{`parse_int(10, 10)`, object.Error{Message: "argument to `parse_int` must be STRING, got INTEGER"}},
+		{`parse_int("10", "2")`, object.Error{Message: "base passed to `parse_int` must be INTEGER, got STRING"}},
+		{`parse_int("10")`, object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestPowAndSqrtBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"pow(2, 10)", 1024},
+		{"pow(-3, 3)", -27},
+		{"pow(7, 0)", 1},
+		{"pow(0, 0)", 1},
+		{"pow(2, 62) == 2 ** 62", true},
+		{"pow(2, 63)", object.Error{Message: "integer overflow"}},
+		{"pow(2, -1)", object.Error{Message: "negative exponent: -1"}},
+		{`pow("2", 2)`, object.Error{Message: "argument to `pow` not supported, got STRING"}},
+		{"pow(2)", object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+		{"sqrt(16)", 4},
+		{"sqrt(17)", 4},
+		{"sqrt(0)", 0},
+		{"sqrt(1)", 1},
+		{"sqrt(9223372036854775807)", 3037000499},
+		{"sqrt(-4)", object.Error{Message: "square root of negative number: -4"}},
+		{"sqrt(true)", object.Error{Message: "argument to `sqrt` not supported, got BOOLEAN"}},
+		{"sqrt()", object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestRandBuiltin(t *testing.T) {
+	expected := rand.New(rand.NewSource(42))
+
+	evaluated := testEval("seed(42); [rand(100), rand(100), rand(10), rand(1)]")
+	array, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	for i, n := range []int64{100, 100, 10, 1} {
+		testIntegerObject(t, array.Elements[i], expected.Int63n(n))
+	}
+
+	testBooleanObject(t, testEval("seed(7); let a = rand(1000); seed(7); a == rand(1000)"), true)
+	testNullObject(t, testEval("seed(1)"))
+
+	testErrorObject(t, testEval("rand(0)"), "argument to `rand` must be positive, got 0")
+	testErrorObject(t, testEval(`rand("10")`), "argument to `rand` not supported, got STRING")
+	testErrorObject(t, testEval("rand()"), "wrong number of arguments. got=0, want=1")
+	testErrorObject(t, testEval("seed(true)"), "argument to `seed` not supported, got BOOLEAN")
+}
+
+func TestFlattenBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"flatten([[1, 2], [3]])", []int64{1, 2, 3}},
+		{"flatten([1, [2, 3], 4])", []int64{1, 2, 3, 4}},
+		{"flatten([[], [1], []])", []int64{1}},
+		{"flatten([])", []int64{}},
+		{"flatten([1, 2])", []int64{1, 2}},
+		{"len(flatten([[1, [2, [3]]]]))", 2},
+		{"flatten([[1, [2, [3]]]])[1][1][0]", 3},
+		{"flatten([[1, [2, [3]]], 4], true)", []int64{1, 2, 3, 4}},
+		{"flatten([[1, [2]]], false)[1][0]", 2},
+		{`let a = flatten([["a"], [true, null]]); [a[0], a[1], a[2]]`, nil},
+		{"let a = [[1]]; flatten(a); a[0][0]", 1},
+		{"flatten(1)", object.Error{Message: "argument to `flatten` must be ARRAY, got INTEGER"}},
+		{"flatten([1], 1)", object.Error{Message: "second argument to `flatten` must be BOOLEAN, got INTEGER"}},
+		{"flatten()", object.Error{Message: "wrong number of arguments. got=0, want=1 or 2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		default:
+			array, ok := evaluated.(*object.Array)
+			if !ok || len(array.Elements) != 3 {
+				t.Errorf("mixed array not flattened. got=%+v", evaluated)
+				continue
+			}
+
+			testStringObject(t, array.Elements[0], "a")
+			testBooleanObject(t, array.Elements[1], true)
+			testNullObject(t, array.Elements[2])
+		}
+	}
+}
+
+func TestUniqueBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"unique([3, 1, 3, 2, 1])", []int64{3, 1, 2}},
+		{"unique([1, 2, 3])", []int64{1, 2, 3}},
+		{"unique([])", []int64{}},
+		{"unique([5, 5, 5])", []int64{5}},
+		{`len(unique(["a", "b", "a", "c", "b"]))`, 3},
+		{`unique(["b", "a", "b"])[1]`, "a"},
+		{"len(unique([true, false, true, false]))", 2},
+		{`len(unique([1, "1", true, 1, "1", true]))`, 3},
+		{"len(unique([[1, 2], [1, 2], [2, 1], null, null]))", 3},
+		{"let a = [1, 1]; unique(a); len(a)", 2},
+		{"unique(1)", object.Error{Message: "argument to `unique` must be ARRAY, got INTEGER"}},
+		{"unique()", object.Error{Message: "wrong number of arguments. got=0, want=1"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestPushBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []int64
+	}{
+		{"push([], 1)", []int64{1}},
+		{"push([1, 2], 3, 4)", []int64{1, 2, 3, 4}},
+		{"let a = [1, 2]; push(a, 3); a", []int64{1, 2}},
+		{"let a = [1, 2]; let b = push(a, 3); let c = push(a, 4); b", []int64{1, 2, 3}},
+		{"let a = [1, 2]; let b = push(a, 3); let c = push(a, 4); c", []int64{1, 2, 4}},
+		{"let a = push([1], 2); let b = push(a, 3); let c = push(a, 4); b", []int64{1, 2, 3}},
+		{"let a = [1]; let b = push(a, 2); b[0] = 9; a", []int64{1}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerArray(t, evaluated, tt.expected)
+	}
+}
+
+func TestConcatBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"concat([1, 2], [3])", []int64{1, 2, 3}},
+		{"concat([1], [2, 3], [4, 5])", []int64{1, 2, 3, 4, 5}},
+		{"concat([], [1], [], [2])", []int64{1, 2}},
+		{"concat([], [])", []int64{}},
+		{"let a = [1, 2]; let b = concat(a, [3]); b[0] = 9; a", []int64{1, 2}},
+		{"concat([1], 2)", object.Error{Message: "argument to `concat` not supported, got INTEGER"}},
+		{`concat("a", [1])`, object.Error{Message: "argument to `concat` not supported, got STRING"}},
+		{"concat([1])", object.Error{Message: "wrong number of arguments. got=1, want>=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestRepeatBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"repeat(0, 3)", []int64{0, 0, 0}},
+		{"repeat(7, 1)", []int64{7}},
+		{`repeat("x", 0)`, []int64{}},
+		{`len(repeat("x", 4))`, 4},
+		{`repeat("x", 2)[1]`, "x"},
+		{"let a = repeat([0], 2); a[0][0] = 9; a[1][0]", 9},
+		{"let rows = repeat({}, 2); rows[0][1] = 5; rows[1][1]", 5},
+		{"repeat(0, -1)", object.Error{Message: "count passed to `repeat` cannot be negative, got -1"}},
+		{"repeat(0, 9223372036854775807)", object.Error{Message: "count passed to `repeat` cannot be more than 16777216, got 9223372036854775807"}},
+		{"repeat(0, 16777217)", object.Error{Message: "count passed to `repeat` cannot be more than 16777216, got 16777217"}},
+		{`repeat(0, "3")`, object.Error{Message: "count passed to `repeat` must be INTEGER, got STRING"}},
+		{"repeat(0)", object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestIndexOfBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"index_of([1, 2, 3], 2)", 1},
+		{"index_of([1, 2, 2], 2)", 1},
+		{"index_of([1, 2, 3], 4)", -1},
+		{"index_of([], 1)", -1},
+		{`index_of([1, "1"], "1")`, 1},
+		{"index_of([[1], [2]], [2])", 1},
+		{`index_of("hello", "l")`, 2},
+		{`index_of("hello", "lo")`, 3},
+		{`index_of("hello", "z")`, -1},
+		{`index_of("", "a")`, -1},
+		{`index_of("abc", "")`, 0},
+		{`index_of("abc", 1)`, object.Error{Message: "substring passed to `index_of` must be STRING, got INTEGER"}},
+		{"index_of(1, 1)", object.Error{Message: "argument to `index_of` not supported, got INTEGER"}},
+		{"index_of([1])", object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestZipBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"zip([1, 2], [3, 4])", [][]int64{{1, 3}, {2, 4}}},
+		{"zip([1, 2, 3], [4])", [][]int64{{1, 4}}},
+		{"zip([1], [4, 5, 6])", [][]int64{{1, 4}}},
+		{"zip([], [1, 2])", [][]int64{}},
+		{"zip([], [])", [][]int64{}},
+		{"zip([1], 2)", object.Error{Message: "argument to `zip` must be ARRAY, got INTEGER"}},
+		{`zip("ab", [1])`, object.Error{Message: "argument to `zip` must be ARRAY, got STRING"}},
+		{"zip([1])", object.Error{Message: "wrong number of arguments. got=1, want=2"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case [][]int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("array has wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, pair := range expected {
+				testIntegerArray(t, array.Elements[i], pair)
+			}
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestSliceBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"slice([1, 2, 3, 4], 1, 3)", []int64{2, 3}},
+		{"slice([1, 2, 3, 4], 2)", []int64{3, 4}},
+		{"slice([1, 2, 3, 4], -2, 4)", []int64{3, 4}},
+		{"slice([1, 2, 3, 4], -10, 10)", []int64{1, 2, 3, 4}},
+		{"slice([1, 2, 3, 4], 3, 1)", []int64{}},
+		{"let a = [1, 2, 3]; let b = slice(a, 0, 2); b[0] = 9; a", []int64{1, 2, 3}},
+		{`slice("hello", 1, 4)`, "ell"},
+		{`slice("hello", 3, 100)`, "lo"},
+		{`slice("hello", 4, 2)`, ""},
+		{`slice([1, 2], "a", 1)`, object.Error{Message: "slice bound must be INTEGER, got STRING"}},
+		{`slice("hello", 0, true)`, object.Error{Message: "slice bound must be INTEGER, got BOOLEAN"}},
+		{"slice(1, 0, 1)", object.Error{Message: "argument to `slice` not supported, got INTEGER"}},
+		{"slice([1])", object.Error{Message: "wrong number of arguments. got=1, want=2 or 3"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestOutputBuiltins(t *testing.T) {
+	defer SetOutput(output)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`puts("hello", 1, [true])`, "hello\n1\n[true]\n"},
+		{`puts()`, ""},
+		{`print("a", 1); print("b")`, "a 1b"},
+		{`print()`, ""},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		SetOutput(&out)
+
+		testNullObject(t, testEval(tt.input))
+
+		if out.String() != tt.expected {
+			t.Errorf("%s: wrong output. got=%q, want=%q", tt.input, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestInputBuiltin(t *testing.T) {
+	defer func(r *bufio.Reader) { inputReader = r }(inputReader)
+	inputReader = bufio.NewReader(strings.NewReader("first line\nsecond line\r\nno newline\nprompted"))
+
+	testStringObject(t, testEval("input()"), "first line")
+	testStringObject(t, testEval("input()"), "second line")
+	testStringObject(t, testEval("input()"), "no newline")
+
+	defer SetOutput(output)
+	var out bytes.Buffer
+	SetOutput(&out)
+
+	testStringObject(t, testEval(`input("> ")`), "prompted")
+	if out.String() != "> " {
+		t.Errorf("wrong prompt written. got=%q", out.String())
+	}
+
+	testNullObject(t, testEval("input()"))
+
+	testErrorObject(t, testEval("input(1)"), "argument to `input` must be STRING, got INTEGER")
+	testErrorObject(t, testEval(`input("a", "b")`), "wrong number of arguments. got=2, want=0 or 1")
+}
+
+func TestClockBuiltin(t *testing.T) {
+	defer func(f func() time.Time) { nowFunc = f }(nowFunc)
+	nowFunc = func() time.Time { return time.Unix(1500, 250*int64(time.Millisecond)) }
+
+	testIntegerObject(t, testEval("clock()"), 1500250)
+	testErrorObject(t, testEval("clock(1)"), "wrong number of arguments. got=1, want=0")
+}
+
+func TestExitBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"exit()", 0},
+		{"exit(3)", 3},
+		{"exit(1); 5", 1},
+		{"let a = exit(2); 5", 2},
+		{"let f = fn() { exit(4); 10 }; f(); 5", 4},
+		{"if (true) { exit(5) } 10", 5},
+		{"puts(exit(6))", 6},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		evaluated := evalProgram(program.Statements, object.NewEnvironment())
+
+		exit, ok := evaluated.(*object.Exit)
+		if !ok {
+			t.Errorf("object is not Exit. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
 
-			if errObj.Message != expected {
-				t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
-			}
-		case nil:
-			testNullObject(t, evaluated)
+		if exit.Code != tt.expected {
+			t.Errorf("exit has wrong code. got=%d, want=%d", exit.Code, tt.expected)
 		}
 	}
+
+	testErrorObject(t, testEval(`exit("a")`), "argument to `exit` must be INTEGER, got STRING")
 }
 
 func TestArrayLiterals(t *testing.T) {
@@ -392,6 +2117,18 @@ func TestArrayIndexExpression(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
+			nil,
+		},
+		{
+			"[][-1]",
 			nil,
 		},
 	}
@@ -407,6 +2144,79 @@ func TestArrayIndexExpression(t *testing.T) {
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4][1:3]", []int64{2, 3}},
+		{"[1, 2, 3, 4][:2]", []int64{1, 2}},
+		{"[1, 2, 3, 4][2:]", []int64{3, 4}},
+		{"[1, 2, 3, 4][:]", []int64{1, 2, 3, 4}},
+		{"[1, 2, 3, 4][-2:]", []int64{3, 4}},
+		{"[1, 2, 3, 4][:-1]", []int64{1, 2, 3}},
+		{"[1, 2, 3, 4][1:100]", []int64{2, 3, 4}},
+		{"[1, 2, 3, 4][-100:1]", []int64{1}},
+		{"[1, 2, 3, 4][3:1]", []int64{}},
+		{"[][0:1]", []int64{}},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[:2]`, "he"},
+		{`"hello"[3:]`, "lo"},
+		{`"hello"[-3:-1]`, "ll"},
+		{`"hello"[4:2]`, ""},
+		{`""[0:10]`, ""},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if str.Value != expected {
+				t.Errorf("String has wrong value. want=%q, got=%q", expected, str.Value)
+			}
+		}
+	}
+}
+
+func TestSliceDoesNotAlias(t *testing.T) {
+	input := `let a = [1, 2, 3]; let b = a[0:2]; push(b, 4); a[2];`
+
+	testIntegerObject(t, testEval(input), 3)
+}
+
+func TestStringIndexExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[-1]`, "c"},
+		{`"abc"[-3]`, "a"},
+		{`"abc"[3]`, nil},
+		{`"abc"[-4]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := tt.expected.(string)
+		if ok {
+			testStringObject(t, evaluated, str)
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two";
 	{
@@ -493,6 +2303,301 @@ func TestHashIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestMemberAccess(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let h = {"name": 5}; h.name`, 5},
+		{`let h = {"name": 5}; h.name == h["name"]`, true},
+		{`let h = {"inner": {"x": 3}}; h.inner.x`, 3},
+		{`let h = {"f": fn(x) { x * 2 }}; h.f(4)`, 8},
+		{`let h = {}; h.missing`, nil},
+		{`let h = {"a": 1}; h.a = 2; h.a`, 2},
+		{`[1, 2].name`, object.Error{Message: "member access not supported: ARRAY"}},
+		{`let x = 5; x.name`, object.Error{Message: "member access not supported: INTEGER"}},
+		{`"str".len`, object.Error{Message: "member access not supported: STRING"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestCompoundAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let x = 5; x += 3;", 8},
+		{"let x = 5; x += 3; x", 8},
+		{"let x = 5; x -= 7; x", -2},
+		{"let x = 5; x *= 2 + 1; x", 15},
+		{"let x = 20; x /= 6; x", 3},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{"let a = [1, 2]; a[1] += 10; a[1]", 12},
+		{"let a = [1, 2]; let n = 0; let f = fn() { n++; 0 }; a[f()] += 5; [a[0], n]", []int64{6, 1}},
+		{"let n = 0; let f = fn() { n++; [1] }; f()[0] *= 3; n", 1},
+		{"let a = [1]; a[5] += 1", object.Error{Message: "type mismatch: NULL + INTEGER"}},
+		{`let h = {"n": 1}; h.n *= 5; h.n`, 5},
+		{"let total = 0; for (let i = 1; i <= 4; i++) { total += i; }; total", 10},
+		{"x += 1", object.Error{Message: "identifier not found: x"}},
+		{"const c = 1; c += 1", object.Error{Message: "cannot assign to constant: c"}},
+		{`let x = 1; x += "a"`, object.Error{Message: "type mismatch: INTEGER + STRING"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestPostfixExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 5; i++; i", 6},
+		{"let i = 5; i--; i", 4},
+		{"let i = 5; i++", 5},
+		{"let i = 5; let j = i--; j * 10 + i", 54},
+		{"let total = 0; for (let i = 0; i < 5; i++) { total++; }; total", 5},
+		{"let n = 10; do { n--; } while (n > 0); n", 0},
+		{"let c = 0; let inc = fn() { c++ }; inc(); inc(); c", 2},
+		{"let x = 5; let y = 2; x - -y", 7},
+		{"let x = 5; let y = 2; x-- - y", 3},
+		{"let i = 9223372036854775807; i++", object.Error{Message: "integer overflow"}},
+		{`let s = "a"; s++`, object.Error{Message: "unknown operator: STRING++"}},
+		{"let b = true; b--", object.Error{Message: "unknown operator: BOOLEAN--"}},
+		{"missing++", object.Error{Message: "identifier not found: missing"}},
+		{"const k = 1; k++", object.Error{Message: "cannot assign to constant: k"}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case object.Error:
+			testErrorObject(t, evaluated, expected.Message)
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = 1; a = 2; a", 2},
+		{"let a = 1; a = 2", 2},
+		{"let a = 1; let b = 2; a = b = 3; a + b", 6},
+		{"let a = 1; let f = fn() { a = 5 }; f(); a", 5},
+		{"b = 2", "identifier not found: b"},
+		{"5 = 2", "invalid assignment target: 5"},
+		{"let a = 1; a = b", "identifier not found: b"},
+		{"let a = 1; a[0] = 2", "index assignment not supported: INTEGER"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const x = 1; x", 1},
+		{"const y = 1; let z = y + 1; z", 2},
+		{"const x = 1; x = 2;", "cannot assign to constant: x"},
+		{"const x = 1; let x = 2;", "cannot assign to constant: x"},
+		{"const x = 1; const x = 2;", "cannot assign to constant: x"},
+		{"const x = 1; let f = fn() { x = 2 }; f()", "cannot assign to constant: x"},
+		{"const x = 1; let f = fn(x) { x = 2; x }; f(5)", 2},
+		{"const x = 1; let f = fn() { let x = 2; x }; f()", 2},
+		{"const x = 1; let f = fn() { let x = 2; x }; f(); x", 1},
+		{"const x = 1; let f = fn() { let [x] = [2]; x }; f()", 2},
+		{"const x = 1; let f = fn() { let x = 2; x = 3; x }; f()", 3},
+		{"const x = 1; let f = fn() { x++ }; f()", "cannot assign to constant: x"},
+		{"const x = 1; let f = fn() { x += 1 }; f()", "cannot assign to constant: x"},
+		{"let x = 1; x = 2; x", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestHashIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`let h = {"k": 1}; h["k"]`, 1},
+		{`let h = {"k": 1}; h["missing"]`, nil},
+		{`let h = {}; h["k"] = 5; h["k"]`, 5},
+		{`let h = {}; h["k"] = 5`, 5},
+		{`let h = {"k": 1}; h["k"] = 2; h["k"]`, 2},
+		{`let h = {}; h[1] = 10; h["1"] = 20; h[1]`, 10},
+		{`let h = {}; h[1] = 10; h["1"] = 20; h["1"]`, 20},
+		{`let h = {}; h[true] = 3; h[true]`, 3},
+		{`let h = {}; h[fn(x) { x }] = 1`, "unusable as hash key: FUNCTION"},
+		{`{}[fn(x) { x }]`, "unusable as hash key: FUNCTION"},
+		{`let h = {}; let f = fn() { h["k"] = 7 }; f(); h["k"]`, 7},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		case nil:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	evaluated := testEval(`let h = {"k": 1}; h["k"] = 2; h`)
+	hash, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("object is not Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(hash.Pairs) != 1 {
+		t.Errorf("overwriting a key changed the number of pairs. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestArrayIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = [1, 2, 3]; a[0] = 10; a", []int64{10, 2, 3}},
+		{"let a = [1, 2, 3]; a[2] = 10", 10},
+		{"let a = [1, 2, 3]; a[-1] = 10; a", []int64{1, 2, 10}},
+		{"let a = [[1, 2], [3, 4]]; a[1][0] = 10; a[1]", []int64{10, 4}},
+		{"let a = [1, 2, 3]; let b = a; b[0] = 10; a", []int64{10, 2, 3}},
+		{"let a = [1, 2, 3]; let b = rest(a); b[0] = 10; a", []int64{1, 2, 3}},
+		{"let a = [1, 2, 3]; a[3] = 10", "index out of range: 3"},
+		{"let a = [1, 2, 3]; a[-4] = 10", "index out of range: -4"},
+		{"let a = []; a[0] = 10", "index out of range: 0"},
+		{`let a = [1]; a["0"] = 10`, "array index must be INTEGER, got STRING"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(5)`, `5`},
+		{`quote(5 + 8)`, `(5 + 8)`},
+		{`quote(foobar)`, `foobar`},
+		{`quote(foobar + barfoo)`, `(foobar + barfoo)`},
+	}
+
+	for _, tt := range tests {
+		testQuoteObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestQuoteUnquote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`quote(unquote(4))`, `4`},
+		{`quote(unquote(4 + 4))`, `8`},
+		{`quote(8 + unquote(4 + 4))`, `(8 + 8)`},
+		{`quote(unquote(4 + 4) + 8)`, `(8 + 8)`},
+		{`let foobar = 8; quote(foobar)`, `foobar`},
+		{`let foobar = 8; quote(unquote(foobar))`, `8`},
+		{`quote(unquote(true))`, `true`},
+		{`quote(unquote(true == false))`, `false`},
+		{`quote(unquote(null))`, `null`},
+		{`quote(unquote(quote(4 + 4)))`, `(4 + 4)`},
+		{`let quotedInfixExpression = quote(4 + 4); quote(unquote(4 + 4) + unquote(quotedInfixExpression))`, `(8 + (4 + 4))`},
+	}
+
+	for _, tt := range tests {
+		testQuoteObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func testQuoteObject(t *testing.T, obj object.Object, expected string) bool {
+	quote, ok := obj.(*object.Quote)
+	if !ok {
+		t.Errorf("object is not Quote. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if quote.Node == nil {
+		t.Errorf("quote.Node is nil")
+		return false
+	}
+
+	if quote.Node.String() != expected {
+		t.Errorf("not equal. got=%q, want=%q", quote.Node.String(), expected)
+		return false
+	}
+
+	return true
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -517,6 +2622,57 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testIntegerArray(t *testing.T, obj object.Object, expected []int64) bool {
+	array, ok := obj.(*object.Array)
+	if !ok {
+		t.Errorf("object is not Array. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if len(array.Elements) != len(expected) {
+		t.Errorf("array has wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+		return false
+	}
+
+	for i, e := range expected {
+		if !testIntegerObject(t, array.Elements[i], e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%q, want=%q", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+
+	return true
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
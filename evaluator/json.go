@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+// builtinParseJSON decodes a JSON string into Monkey values. Objects become hashes, arrays become arrays, and strings,
+// booleans and null become their Monkey equivalents. Monkey has no floats, so only whole numbers can be decoded.
+func builtinParseJSON(args ...object.Object) object.Object {
+	values, err := stringArgs("parse_json", args, 1)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(values[0]))
+	decoder.UseNumber()
+
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return newError("could not parse JSON: %s", err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return newError("could not parse JSON: unexpected data after top-level value")
+	}
+
+	return fromJSON(decoded)
+}
+
+// fromJSON converts a value decoded by encoding/json into a Monkey object.
+func fromJSON(value interface{}) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case string:
+		return &object.String{Value: value}
+	case json.Number:
+		integer, err := value.Int64()
+		if err != nil {
+			return newError("could not parse JSON: number %s is not an INTEGER", value)
+		}
+
+		return &object.Integer{Value: integer}
+	case []interface{}:
+		elements := make([]object.Object, len(value))
+		for i, e := range value {
+			elements[i] = fromJSON(e)
+			if isError(elements[i]) {
+				return elements[i]
+			}
+		}
+
+		return &object.Array{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[object.HashKey]object.HashPair, len(value))
+		for k, v := range value {
+			key := &object.String{Value: k}
+
+			converted := fromJSON(v)
+			if isError(converted) {
+				return converted
+			}
+
+			pairs[key.HashKey()] = object.HashPair{Key: key, Value: converted}
+		}
+
+		return &object.Hash{Pairs: pairs}
+	}
+
+	return newError("could not parse JSON: unsupported value %v", value)
+}
+
+// builtinToJSON encodes a Monkey value as a JSON string. Hashes can only be encoded if all their keys are strings, and
+// functions can't be encoded at all.
+func builtinToJSON(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("wrong number of arguments. got=%d, want=1", len(args))
+	}
+
+	value, errObj := toJSON(args[0])
+	if errObj != nil {
+		return errObj
+	}
+
+	var out bytes.Buffer
+
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(value); err != nil {
+		return newError("could not convert to JSON: %s", err)
+	}
+
+	return &object.String{Value: strings.TrimSuffix(out.String(), "\n")}
+}
+
+// toJSON converts a Monkey object into a value which can be encoded by encoding/json. If the object can't be converted,
+// the error to return from the builtin is returned instead.
+func toJSON(obj object.Object) (interface{}, object.Object) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		elements := make([]interface{}, len(obj.Elements))
+		for i, e := range obj.Elements {
+			converted, err := toJSON(e)
+			if err != nil {
+				return nil, err
+			}
+
+			elements[i] = converted
+		}
+
+		return elements, nil
+	case *object.Hash:
+		pairs := make(map[string]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			key, ok := pair.Key.(*object.String)
+			if !ok {
+				return nil, newError("cannot convert hash key of type %s to JSON, want STRING", pair.Key.Type())
+			}
+
+			converted, err := toJSON(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			pairs[key.Value] = converted
+		}
+
+		return pairs, nil
+	}
+
+	return nil, newError("cannot convert %s to JSON", obj.Type())
+}
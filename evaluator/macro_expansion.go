@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/ollybritton/monkey/ast"
+	"github.com/ollybritton/monkey/object"
+)
+
+// DefineMacros finds every top-level macro definition in the program, such as "let m = macro(x) { x };", binds it in
+// the environment and removes it from the program.
+func DefineMacros(program *ast.Program, environment *object.Environment) {
+	definitions := []int{}
+
+	for i, statement := range program.Statements {
+		if isMacroDefinition(statement) {
+			addMacro(statement, environment)
+			definitions = append(definitions, i)
+		}
+	}
+
+	for i := len(definitions) - 1; i >= 0; i-- {
+		definitionIndex := definitions[i]
+		program.Statements = append(
+			program.Statements[:definitionIndex],
+			program.Statements[definitionIndex+1:]...,
+		)
+	}
+}
+
+// isMacroDefinition returns true if the statement is a let statement binding a macro literal.
+func isMacroDefinition(node ast.Statement) bool {
+	letStatement, ok := node.(*ast.LetStatement)
+	if !ok {
+		return false
+	}
+
+	_, ok = letStatement.Value.(*ast.MacroLiteral)
+	return ok && letStatement.Name != nil
+}
+
+// addMacro binds the macro defined by a let statement in the environment.
+func addMacro(stmt ast.Statement, environment *object.Environment) {
+	letStatement, _ := stmt.(*ast.LetStatement)
+	macroLiteral, _ := letStatement.Value.(*ast.MacroLiteral)
+
+	macro := &object.Macro{
+		Parameters: macroLiteral.Parameters,
+		Env:        environment,
+		Body:       macroLiteral.Body,
+	}
+
+	environment.Set(letStatement.Name.Value, macro)
+}
+
+// ExpandMacros replaces every call to a macro defined in the environment with the result of evaluating the macro. The
+// arguments are passed to the macro quoted, and the macro must return a quoted node. An error is returned if any macro
+// returns something else, in which case the call to that macro is left in place.
+func ExpandMacros(program ast.Node, environment *object.Environment) (ast.Node, error) {
+	var err error
+
+	expanded := ast.Modify(program, func(node ast.Node) ast.Node {
+		callExpression, ok := node.(*ast.CallExpression)
+		if !ok {
+			return node
+		}
+
+		macro, ok := isMacroCall(callExpression, environment)
+		if !ok {
+			return node
+		}
+
+		args := quoteArgs(callExpression)
+		evalEnv := extendMacroEnv(macro, args)
+
+		evaluated := Eval(macro.Body, evalEnv)
+
+		quote, ok := evaluated.(*object.Quote)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("macro %s returned %s, expected QUOTE", callExpression.Function, evaluated.Inspect())
+			}
+
+			return node
+		}
+
+		return quote.Node
+	})
+
+	return expanded, err
+}
+
+// isMacroCall returns the macro being called if the call expression calls a macro defined in the environment.
+func isMacroCall(exp *ast.CallExpression, environment *object.Environment) (*object.Macro, bool) {
+	identifier, ok := exp.Function.(*ast.Identifier)
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := environment.Get(identifier.Value)
+	if !ok {
+		return nil, false
+	}
+
+	macro, ok := obj.(*object.Macro)
+	if !ok {
+		return nil, false
+	}
+
+	return macro, true
+}
+
+// quoteArgs wraps each argument to a macro call in an object.Quote.
+func quoteArgs(exp *ast.CallExpression) []*object.Quote {
+	args := []*object.Quote{}
+
+	for _, a := range exp.Arguments {
+		args = append(args, &object.Quote{Node: a})
+	}
+
+	return args
+}
+
+// extendMacroEnv creates the environment a macro's body is evaluated in, binding its parameters to the quoted arguments.
+func extendMacroEnv(macro *object.Macro, args []*object.Quote) *object.Environment {
+	extended := object.NewExtendedEnvironment(macro.Env)
+
+	for paramIdx, param := range macro.Parameters {
+		if paramIdx < len(args) {
+			extended.Set(param.Value, args[paramIdx])
+		}
+	}
+
+	return extended
+}
@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/ollybritton/monkey/ast"
+	"github.com/ollybritton/monkey/object"
+	"github.com/ollybritton/monkey/token"
+)
+
+// quote wraps a node in an object.Quote without evaluating it, after evaluating any unquote() calls inside it.
+func quote(node ast.Node, environment *object.Environment) object.Object {
+	node = evalUnquoteCalls(node, environment)
+	return &object.Quote{Node: node}
+}
+
+// evalUnquoteCalls replaces every call to unquote() inside the node with the result of evaluating its argument.
+func evalUnquoteCalls(quoted ast.Node, environment *object.Environment) ast.Node {
+	return ast.Modify(quoted, func(node ast.Node) ast.Node {
+		if !isUnquoteCall(node) {
+			return node
+		}
+
+		call, ok := node.(*ast.CallExpression)
+		if !ok || len(call.Arguments) != 1 {
+			return node
+		}
+
+		unquoted := Eval(call.Arguments[0], environment)
+		return convertObjectToASTNode(unquoted, node)
+	})
+}
+
+// isUnquoteCall returns true if the node is a call to unquote().
+func isUnquoteCall(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+
+	return call.Function.TokenLiteral() == "unquote"
+}
+
+// convertObjectToASTNode converts the result of evaluating an unquote() call back into a node, so that it can be
+// spliced into the quoted tree. Objects which can't be converted leave the original node in place.
+func convertObjectToASTNode(obj object.Object, original ast.Node) ast.Node {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		t := token.Token{Type: token.INT, Literal: fmt.Sprintf("%d", obj.Value)}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
+	case *object.Boolean:
+		var t token.Token
+		if obj.Value {
+			t = token.Token{Type: token.TRUE, Literal: "true"}
+		} else {
+			t = token.Token{Type: token.FALSE, Literal: "false"}
+		}
+
+		return &ast.Boolean{Token: t, Value: obj.Value}
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}
+	case *object.Null:
+		return &ast.NullLiteral{Token: token.Token{Type: token.NULL, Literal: "null"}}
+	case *object.Quote:
+		return obj.Node
+	default:
+		return original
+	}
+}
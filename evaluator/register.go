@@ -0,0 +1,54 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+// registeredBuiltins holds the builtins added by RegisterBuiltin and OverrideBuiltin. Identifiers are looked up in the
+// environment first, then in registeredBuiltins and finally in the core builtins.
+var registeredBuiltins = map[string]*object.Builtin{}
+
+// RegisterBuiltin makes a Go function available to Monkey programs under the given name. Registering the same name again
+// replaces the earlier function. It panics if the name is already used by a core builtin such as `len`; use
+// OverrideBuiltin to replace one of those deliberately.
+func RegisterBuiltin(name string, fn object.BuiltinFunction) {
+	register("RegisterBuiltin", name, &object.Builtin{Fn: fn})
+}
+
+// RegisterEnvBuiltin is like RegisterBuiltin, but the function is also given the environment it is called from so that
+// it can read or change the variables in scope.
+func RegisterEnvBuiltin(name string, fn object.BuiltinFn) {
+	register("RegisterEnvBuiltin", name, &object.Builtin{EnvFn: fn})
+}
+
+// OverrideBuiltin is like RegisterBuiltin, but is allowed to replace a core builtin.
+func OverrideBuiltin(name string, fn object.BuiltinFunction) {
+	registeredBuiltins[name] = &object.Builtin{Fn: fn}
+}
+
+// OverrideEnvBuiltin is like RegisterEnvBuiltin, but is allowed to replace a core builtin.
+func OverrideEnvBuiltin(name string, fn object.BuiltinFn) {
+	registeredBuiltins[name] = &object.Builtin{EnvFn: fn}
+}
+
+// register adds a builtin to registeredBuiltins, panicking if the name is already used by a core builtin. caller is the
+// name of the exported function used, for the panic message.
+func register(caller, name string, builtin *object.Builtin) {
+	if _, ok := builtins[name]; ok {
+		panic(fmt.Sprintf("evaluator: %s called with the name of a core builtin: %s", caller, name))
+	}
+
+	registeredBuiltins[name] = builtin
+}
+
+// lookupBuiltin finds the builtin with the given name, preferring registered builtins over the core ones.
+func lookupBuiltin(name string) (*object.Builtin, bool) {
+	if builtin, ok := registeredBuiltins[name]; ok {
+		return builtin, true
+	}
+
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ollybritton/monkey/object"
+)
+
+func TestRegisterBuiltin(t *testing.T) {
+	defer delete(registeredBuiltins, "double")
+
+	RegisterBuiltin("double", func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+
+		integer, ok := args[0].(*object.Integer)
+		if !ok {
+			return newError("argument to `double` not supported, got %s", args[0].Type())
+		}
+
+		return &object.Integer{Value: integer.Value * 2}
+	})
+
+	testIntegerObject(t, testEval("double(21)"), 42)
+	testIntegerObject(t, testEval("let apply = fn(f, x) { f(x) }; apply(double, 2)"), 4)
+	testErrorObject(t, testEval(`double("a")`), "argument to `double` not supported, got STRING")
+
+	// Bindings in the environment still take priority over builtins.
+	testIntegerObject(t, testEval("let double = fn(x) { x }; double(21)"), 21)
+}
+
+func TestRegisterBuiltinCoreName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterBuiltin did not panic when given the name of a core builtin")
+		}
+	}()
+
+	RegisterBuiltin("len", func(args ...object.Object) object.Object { return NULL })
+}
+
+func TestOverrideBuiltin(t *testing.T) {
+	defer delete(registeredBuiltins, "len")
+
+	OverrideBuiltin("len", func(args ...object.Object) object.Object {
+		return &object.Integer{Value: -1}
+	})
+
+	testIntegerObject(t, testEval(`len("hello")`), -1)
+}
+
+func TestRegisterEnvBuiltin(t *testing.T) {
+	defer delete(registeredBuiltins, "lookup")
+
+	RegisterEnvBuiltin("lookup", func(env *object.Environment, args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError("wrong number of arguments. got=%d, want=1", len(args))
+		}
+
+		name, ok := args[0].(*object.String)
+		if !ok {
+			return newError("argument to `lookup` not supported, got %s", args[0].Type())
+		}
+
+		if value, ok := env.Get(name.Value); ok {
+			return value
+		}
+
+		return NULL
+	})
+
+	testIntegerObject(t, testEval(`let x = 5; lookup("x")`), 5)
+	testIntegerObject(t, testEval(`let x = 5; let f = fn(x) { lookup("x") }; f(7)`), 7)
+	testIntegerObject(t, testEval(`let x = 5; sort([1, 2], fn(a, b) { a * lookup("x") > b * lookup("x") })[0]`), 2)
+	testNullObject(t, testEval(`lookup("missing")`))
+}
+
+func TestRegisterEnvBuiltinCoreName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterEnvBuiltin did not panic when given the name of a core builtin")
+		}
+	}()
+
+	RegisterEnvBuiltin("env", func(env *object.Environment, args ...object.Object) object.Object { return NULL })
+}
+
+func TestOverrideEnvBuiltin(t *testing.T) {
+	defer delete(registeredBuiltins, "len")
+
+	OverrideEnvBuiltin("len", func(env *object.Environment, args ...object.Object) object.Object {
+		return &object.Integer{Value: int64(len(env.Names()))}
+	})
+
+	testIntegerObject(t, testEval(`let a = 1; let b = 2; len("hello")`), 2)
+}
@@ -1,20 +1,47 @@
 package lexer
 
-import "github.com/ollybritton/monkey/token"
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ollybritton/monkey/token"
+)
 
 // Lexer represents a lexer for a monkey program.
-// It acts on an ASCII string, not a unicode one for simplicity. If we wanted to use Unicode, we'd have to change l.ch
-// to 'rune', and update the logic for l.input[l.readPosition] as we wouldn't be able to access single bytes now.
+// It reads the input as UTF-8 one rune at a time, so identifiers and strings can contain any Unicode letters. The
+// positions are byte offsets into the input rather than rune counts, so that they can be used to slice the input.
 type Lexer struct {
 	input        string
-	position     int  // current position in input (index of current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	position     int  // current position in input (byte offset of current char)
+	readPosition int  // current reading position in input (byte offset after current char)
+	ch           rune // current char under examination
+	line         int  // line of the current char, starting at 1
+	column       int  // column of the current char, starting at 1
+
+	errors []LexError
+
+	insertSemicolons bool            // whether newlines after the end of a statement are read as semicolons
+	last             token.TokenType // the type of the last token read
+}
+
+// LexError is a problem found in the input by the lexer, such as an unexpected character. The lexer still emits an
+// ILLEGAL token for it so that the rest of the input can be read.
+type LexError struct {
+	Pos  token.Position // the position of the offending character
+	Char rune           // the offending character, or the first character of an invalid literal
+	Msg  string
+}
+
+// Error returns the position and message of the error, such as "1:5: unexpected character '@'".
+func (e LexError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
 }
 
 // New returns a new lexer.
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 
 	// Read one character so the lexer is fully initialised with values when returned.
 	l.readChar()
@@ -22,24 +49,61 @@ func New(input string) *Lexer {
 	return l
 }
 
+// Reset rewinds the lexer to the start of its input, so that the same tokens can be read again.
+func (l *Lexer) Reset() {
+	l.position = 0
+	l.readPosition = 0
+	l.ch = 0
+	l.line = 1
+	l.column = 0
+	l.errors = nil
+	l.last = ""
+
+	l.readChar()
+}
+
+// Tokenize returns every token in the input, ending with the EOF token.
+func Tokenize(input string) []token.Token {
+	return New(input).Tokens()
+}
+
+// Tokens reads all of the remaining tokens from the lexer, up to and including the EOF token.
+func (l *Lexer) Tokens() []token.Token {
+	tokens := []token.Token{}
+
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+
+		if tok.Type == token.EOF {
+			return tokens
+		}
+	}
+}
+
 // isLetter returns true if the character specified is a letter, and false if it is not (kind of self-explanatory if you ask me)
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
 // isDigit returns true if the character is a number.
-func isDigit(ch byte) bool {
-	return '0' <= ch && ch <= '9'
+func isDigit(ch rune) bool {
+	return unicode.IsDigit(ch)
 }
 
 // isWhitespace returns true if the character is a type of whitespace (a space, a tab, a newline or a linefeed)
-func isWhitespace(ch byte) bool {
+func isWhitespace(ch rune) bool {
 	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
-// skipWhitespace consumes whitespace characters.
+// skipWhitespace consumes whitespace characters. When semicolons are being inserted, it stops at a newline which ends a
+// statement, so that it is read as a semicolon.
 func (l *Lexer) skipWhitespace() {
 	for isWhitespace(l.ch) {
+		if l.ch == '\n' && l.insertSemicolons && endsStatement(l.last) {
+			return
+		}
+
 		l.readChar()
 	}
 }
@@ -47,25 +111,39 @@ func (l *Lexer) skipWhitespace() {
 // readChar reads us the next character in the input. If there is no input left to read (i.e. the input is finished or the
 // input is blank) then set the char value to ASCII NUL.
 func (l *Lexer) readChar() {
+	// Once the end of the input has been reached, stay there so that the position of EOF doesn't keep moving.
+	if l.readPosition > len(l.input) {
+		return
+	}
+
+	if l.ch == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
 	// Sets the current char under examination to the null char if there are no more chars left to read.
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 
 	// Basically saying "set the current character to the next character"
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += width
 }
 
-// peekChar returns the next char in the input as a byte.
-func (l *Lexer) peekChar() byte {
+// peekChar returns the next char in the input as a rune.
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
 
-	return l.input[l.readPosition]
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
 }
 
 // readIdentifier reads a set of characters and returns the characters that it read as a string.
@@ -78,46 +156,207 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[startPosition:l.position]
 }
 
-// readNumber reads a set digits and returns the string representation of that number.
-// At the moment, only integers are supported.
-func (l *Lexer) readNumber() string {
+// numberBases maps the prefixes for integers in other bases, such as the "x" in "0xFF", to the digits valid in that base.
+var numberBases = map[rune]string{
+	'x': "0123456789abcdefABCDEF",
+	'X': "0123456789abcdefABCDEF",
+	'o': "01234567",
+	'O': "01234567",
+	'b': "01",
+	'B': "01",
+}
+
+// readNumber reads a set digits and returns the string representation of that number, and whether it is valid.
+// At the moment, only integers are supported. Digits can be separated by underscores to make large numbers easier to
+// read, such as 1_000_000. Hexadecimal, octal and binary integers are written with a prefix, such as 0xFF, 0o17 or 0b1010.
+func (l *Lexer) readNumber() (string, bool) {
 	startPosition := l.position
-	for isDigit(l.ch) {
+
+	if digits, ok := numberBases[l.peekChar()]; ok && l.ch == '0' {
 		l.readChar()
+		l.readChar()
+
+		// Read any letters as well as digits so that invalid numbers like 0xZZ become a single illegal token.
+		digitsPosition := l.position
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.readChar()
+		}
+
+		number := l.input[digitsPosition:l.position]
+		valid := number != "" && validSeparators(number) && strings.Trim(number, digits+"_") == ""
+
+		return l.input[startPosition:l.position], valid
 	}
 
-	return l.input[startPosition:l.position]
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+
+	number := l.input[startPosition:l.position]
+	return number, validSeparators(number)
+}
+
+// validSeparators returns true if every underscore in a number is between two digits.
+func validSeparators(number string) bool {
+	return !strings.HasPrefix(number, "_") && !strings.HasSuffix(number, "_") && !strings.Contains(number, "__")
 }
 
-// readString reads a string of characters.
-func (l *Lexer) readString() string {
+// simpleEscapes maps the character after a backslash in a string to the character it stands for.
+var simpleEscapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'"':  '"',
+	'\\': '\\',
+}
+
+// readString reads a string of characters, decoding any escape sequences, and returns it along with whether it is valid.
+// As well as the escapes in simpleEscapes, \uXXXX is decoded from four hex digits into the UTF-8 bytes for that code
+// point, so "\u00e9" becomes the two bytes of "é". A backslash before any other character is kept as it is. If a \u
+// escape has fewer than four hex digits, the raw contents of the string are returned and the string is not valid.
+func (l *Lexer) readString() (string, bool) {
 	position := l.position + 1
+	valid := true
 
+	var out strings.Builder
 	for {
 		l.readChar()
 
 		if l.ch == '"' || l.ch == 0 {
 			break
 		}
+
+		if l.ch != '\\' {
+			out.WriteRune(l.ch)
+			continue
+		}
+
+		if escaped, ok := simpleEscapes[l.peekChar()]; ok {
+			l.readChar()
+			out.WriteRune(escaped)
+			continue
+		}
+
+		if l.peekChar() != 'u' {
+			out.WriteRune(l.ch)
+			continue
+		}
+
+		l.readChar()
+
+		var code rune
+		for i := 0; i < 4; i++ {
+			digit := strings.IndexRune(hexDigits, unicode.ToLower(l.peekChar()))
+			if digit < 0 {
+				valid = false
+				break
+			}
+
+			l.readChar()
+			code = code*16 + rune(digit)
+		}
+
+		out.WriteRune(code)
+	}
+
+	if !valid {
+		return l.input[position:l.position], false
 	}
 
-	return l.input[position:l.position]
+	return out.String(), true
 }
 
-// newToken returns a new token from a specified token type and literal value, given as a byte.
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+// hexDigits are the digits of a hexadecimal number, in order of their value.
+const hexDigits = "0123456789abcdef"
+
+// newToken returns a new token from a specified token type and literal value, given as a rune.
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(ch),
 	}
 }
 
+// pos returns the position of the current char.
+func (l *Lexer) pos() token.Position {
+	return token.Position{Line: l.line, Column: l.column}
+}
+
 // NextToken returns the next token in the input.
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	start := l.pos()
+	first := l.ch
+
+	tok := l.readToken()
+	tok.Pos, tok.End = start, l.pos()
+
+	if tok.Type == token.ILLEGAL {
+		l.errors = append(l.errors, newLexError(tok, first))
+	}
+
+	l.last = tok.Type
+
+	return tok
+}
+
+// SetInsertSemicolons sets whether the lexer inserts semicolons at newlines, so that statements don't need to end with
+// one. Like in Go, a newline is only read as a semicolon if the token before it could end a statement, such as an
+// identifier, a literal or a closing bracket. This means a newline can still be used after an operator or a comma to
+// continue an expression onto the next line, but multi-line array and hash literals need a trailing comma.
+func (l *Lexer) SetInsertSemicolons(enabled bool) {
+	l.insertSemicolons = enabled
+}
+
+// endsStatement returns true if a token of the given type can be the last token of a statement.
+func endsStatement(t token.TokenType) bool {
+	switch t {
+	case token.IDENT, token.INT, token.STRING, token.TRUE, token.FALSE, token.NULL,
+		token.RPAREN, token.RBRACE, token.RBRACKET,
+		token.RETURN, token.BREAK, token.CONTINUE, token.INCREMENT, token.DECREMENT:
+		return true
+	}
+
+	return false
+}
+
+// followsOperand returns true if a token of the given type can be the last token of an operand. "++" and "--" are
+// only read as postfix operators after an operand, so that "--5" is still read as two minus signs.
+func followsOperand(t token.TokenType) bool {
+	switch t {
+	case token.IDENT, token.INT, token.STRING, token.TRUE, token.FALSE, token.NULL, token.RPAREN, token.RBRACKET:
+		return true
+	}
+
+	return false
+}
+
+// Errors returns the errors found in the input read so far.
+func (l *Lexer) Errors() []LexError {
+	return l.errors
+}
+
+// newLexError creates the error for an ILLEGAL token, where first is the character the token started with.
+func newLexError(tok token.Token, first rune) LexError {
+	err := LexError{Pos: tok.Pos, Char: first}
+
+	switch {
+	case first == '"':
+		err.Msg = fmt.Sprintf("invalid string literal %q", tok.Literal)
+	case isDigit(first):
+		err.Msg = fmt.Sprintf("invalid number literal %q", tok.Literal)
+	default:
+		err.Msg = fmt.Sprintf("unexpected character %q", first)
+	}
+
+	return err
+}
+
+// readToken reads the token starting at the current char, leaving the lexer on the char just after it.
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -129,9 +368,29 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '+' && followsOperand(l.last) {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.INCREMENT, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '-' && followsOperand(l.last) {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.DECREMENT, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -142,14 +401,44 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '*' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.POWER, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
+
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.SLASH_ASSIGN, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
-	case ';':
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
+	case ';', '\n':
+		// Newlines only get this far when they are being read as semicolons, see skipWhitespace.
 		tok = newToken(token.SEMICOLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
@@ -159,6 +448,10 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.COMMA, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		tok = newToken(token.DOT, l.ch)
+	case '?':
+		tok = newToken(token.QUESTION, l.ch)
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
@@ -169,7 +462,13 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.RBRACKET, l.ch)
 	case '"':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+
+		literal, valid := l.readString()
+		tok.Literal = literal
+
+		if !valid {
+			tok.Type = token.ILLEGAL
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -180,7 +479,14 @@ func (l *Lexer) NextToken() token.Token {
 			return tok
 		} else if isDigit(l.ch) {
 			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+
+			literal, valid := l.readNumber()
+			tok.Literal = literal
+
+			if !valid {
+				tok.Type = token.ILLEGAL
+			}
+
 			return tok
 		}
 
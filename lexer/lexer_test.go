@@ -28,6 +28,19 @@ if (5 < 10) {
 "foo bar"
 [1, 2];
 {"foo":"bar"}
+a ? 1 : 2;
+for (let i = 0; i < 10; i = i + 1) {}
+break; continue;
+for k, v in h {}
+2 ** 3 * *4;
+macro(x, y) { x + y; };
+const c = 1;
+do { x } while (y);
+a <= b >= c;
+h.name;
+i++; j--; --5;
+a += 1; a -= 2; a *= 3; a /= 4;
+let n = null;
 `
 
 	tests := []struct {
@@ -120,6 +133,118 @@ if (5 < 10) {
 		{token.COLON, ":"},
 		{token.STRING, "bar"},
 		{token.RBRACE, "}"},
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.FOR, "for"},
+		{token.LPAREN, "("},
+		{token.LET, "let"},
+		{token.IDENT, "i"},
+		{token.ASSIGN, "="},
+		{token.INT, "0"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "i"},
+		{token.LT, "<"},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "i"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "i"},
+		{token.PLUS, "+"},
+		{token.INT, "1"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.BREAK, "break"},
+		{token.SEMICOLON, ";"},
+		{token.CONTINUE, "continue"},
+		{token.SEMICOLON, ";"},
+		{token.FOR, "for"},
+		{token.IDENT, "k"},
+		{token.COMMA, ","},
+		{token.IDENT, "v"},
+		{token.IN, "in"},
+		{token.IDENT, "h"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.INT, "2"},
+		{token.POWER, "**"},
+		{token.INT, "3"},
+		{token.ASTERISK, "*"},
+		{token.ASTERISK, "*"},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.MACRO, "macro"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.COMMA, ","},
+		{token.IDENT, "y"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.PLUS, "+"},
+		{token.IDENT, "y"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, ";"},
+		{token.CONST, "const"},
+		{token.IDENT, "c"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.DO, "do"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "x"},
+		{token.RBRACE, "}"},
+		{token.WHILE, "while"},
+		{token.LPAREN, "("},
+		{token.IDENT, "y"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "h"},
+		{token.DOT, "."},
+		{token.IDENT, "name"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "i"},
+		{token.INCREMENT, "++"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "j"},
+		{token.DECREMENT, "--"},
+		{token.SEMICOLON, ";"},
+		{token.MINUS, "-"},
+		{token.MINUS, "-"},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "n"},
+		{token.ASSIGN, "="},
+		{token.NULL, "null"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -140,3 +265,338 @@ if (5 < 10) {
 		}
 	}
 }
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"1000000", token.INT, "1000000"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"1_2_3", token.INT, "1_2_3"},
+		{"1__000", token.ILLEGAL, "1__000"},
+		{"1000_", token.ILLEGAL, "1000_"},
+		{"0xFF", token.INT, "0xFF"},
+		{"0Xff", token.INT, "0Xff"},
+		{"0xdead_beef", token.INT, "0xdead_beef"},
+		{"0b1010", token.INT, "0b1010"},
+		{"0o17", token.INT, "0o17"},
+		{"0xZZ", token.ILLEGAL, "0xZZ"},
+		{"0x", token.ILLEGAL, "0x"},
+		{"0b102", token.ILLEGAL, "0b102"},
+		{"0o8", token.ILLEGAL, "0o8"},
+		{"0x_FF", token.ILLEGAL, "0x_FF"},
+		{"0", token.INT, "0"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Errorf("%q - Token type wrong. expected=%q, got=%q", tt.input, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("%q - Token literal wrong. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%q - expected EOF after number, got=%q", tt.input, next.Type)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"plain"`, token.STRING, "plain"},
+		{`"a\nb\tc"`, token.STRING, "a\nb\tc"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\u00e9"`, token.STRING, "é"},
+		{`"\u00E9t\u00e9"`, token.STRING, "été"},
+		{`"\u03bb"`, token.STRING, "λ"},
+		{`"\u20AC"`, token.STRING, "€"},
+		{`"\q"`, token.STRING, `\q`},
+		{`"\u12"`, token.ILLEGAL, `\u12`},
+		{`"\u12g4"`, token.ILLEGAL, `\u12g4`},
+		{`"\u"`, token.ILLEGAL, `\u`},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Errorf("%s - Token type wrong. expected=%q, got=%q", tt.input, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("%s - Token literal wrong. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%s - expected EOF after string, got=%q", tt.input, next.Type)
+		}
+	}
+
+	// Decoded code points are stored as UTF-8, so a single escape can produce more than one byte.
+	if tok := New(`"\u00e9"`).NextToken(); len(tok.Literal) != 2 {
+		t.Errorf("wrong number of bytes in decoded escape. expected=2, got=%d", len(tok.Literal))
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := `let café = "naïve λ";
+λ(über_x, 日本);
+"→" ✓`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "café"},
+		{token.ASSIGN, "="},
+		{token.STRING, "naïve λ"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "λ"},
+		{token.LPAREN, "("},
+		{token.IDENT, "über_x"},
+		{token.COMMA, ","},
+		{token.IDENT, "日本"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, ";"},
+		{token.STRING, "→"},
+		{token.ILLEGAL, "✓"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Token type wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Token literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestPositions(t *testing.T) {
+	input := `let café = "λ";
+  x >= 10`
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.LET, token.Position{Line: 1, Column: 1}, token.Position{Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Line: 1, Column: 5}, token.Position{Line: 1, Column: 9}},
+		{token.ASSIGN, token.Position{Line: 1, Column: 10}, token.Position{Line: 1, Column: 11}},
+		{token.STRING, token.Position{Line: 1, Column: 12}, token.Position{Line: 1, Column: 15}},
+		{token.SEMICOLON, token.Position{Line: 1, Column: 15}, token.Position{Line: 1, Column: 16}},
+		{token.IDENT, token.Position{Line: 2, Column: 3}, token.Position{Line: 2, Column: 4}},
+		{token.GT_EQ, token.Position{Line: 2, Column: 5}, token.Position{Line: 2, Column: 7}},
+		{token.INT, token.Position{Line: 2, Column: 8}, token.Position{Line: 2, Column: 10}},
+		{token.EOF, token.Position{Line: 2, Column: 10}, token.Position{Line: 2, Column: 10}},
+		{token.EOF, token.Position{Line: 2, Column: 10}, token.Position{Line: 2, Column: 10}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Token type wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Errorf("tests[%d] - Token position wrong. expected=%s, got=%s", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Errorf("tests[%d] - Token end wrong. expected=%s, got=%s", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	input := `let a = @;
+let $b = "\u12" + 0x;`
+
+	l := New(input)
+	tokens := l.Tokens()
+
+	if tokens[len(tokens)-1].Type != token.EOF {
+		t.Fatalf("lexer did not continue to EOF after errors. got=%+v", tokens[len(tokens)-1])
+	}
+
+	expected := []LexError{
+		{Pos: token.Position{Line: 1, Column: 9}, Char: '@', Msg: "unexpected character '@'"},
+		{Pos: token.Position{Line: 2, Column: 5}, Char: '$', Msg: "unexpected character '$'"},
+		{Pos: token.Position{Line: 2, Column: 10}, Char: '"', Msg: `invalid string literal "\\u12"`},
+		{Pos: token.Position{Line: 2, Column: 19}, Char: '0', Msg: `invalid number literal "0x"`},
+	}
+
+	errors := l.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)", len(expected), len(errors), errors)
+	}
+
+	for i, e := range expected {
+		if errors[i] != e {
+			t.Errorf("errors[%d] wrong. expected=%+v, got=%+v", i, e, errors[i])
+		}
+	}
+
+	if msg := errors[0].Error(); msg != "1:9: unexpected character '@'" {
+		t.Errorf("error message wrong. got=%q", msg)
+	}
+
+	l.Reset()
+	if len(l.Errors()) != 0 {
+		t.Errorf("errors not cleared by reset. got=%v", l.Errors())
+	}
+}
+
+func TestInsertSemicolons(t *testing.T) {
+	input := `let a = [1,
+	2]
+a[0] +
+	b()
+
+i++
+return`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "a"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.PLUS, "+"},
+		{token.IDENT, "b"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "i"},
+		{token.INCREMENT, "++"},
+		{token.SEMICOLON, "\n"},
+		{token.RETURN, "return"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	l.SetInsertSemicolons(true)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - Token type wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - Token literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	// Without the option, newlines are only whitespace.
+	for _, tok := range Tokenize(input) {
+		if tok.Type == token.SEMICOLON {
+			t.Errorf("semicolon inserted without the option being set at %s", tok.Pos)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	inputs := []string{
+		"",
+		"let five = 5;",
+		`fn(x, y) { x + y; } "hello" [1, 2]`,
+		"1__000 @",
+	}
+
+	for _, input := range inputs {
+		expected := []token.Token{}
+		l := New(input)
+		for {
+			tok := l.NextToken()
+			expected = append(expected, tok)
+
+			if tok.Type == token.EOF {
+				break
+			}
+		}
+
+		for _, tokens := range [][]token.Token{New(input).Tokens(), Tokenize(input)} {
+			if len(tokens) != len(expected) {
+				t.Errorf("%q - wrong number of tokens. expected=%d, got=%d", input, len(expected), len(tokens))
+				continue
+			}
+
+			for i, tok := range tokens {
+				if tok != expected[i] {
+					t.Errorf("%q - tokens[%d] wrong. expected=%+v, got=%+v", input, i, expected[i], tok)
+				}
+			}
+
+			if tokens[len(tokens)-1].Type != token.EOF {
+				t.Errorf("%q - last token is not EOF. got=%q", input, tokens[len(tokens)-1].Type)
+			}
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	input := `let add = fn(x, y) { x + y; }; add(1_000, 0xFF) == "done"`
+
+	l := New(input)
+	first := l.Tokens()
+
+	l.Reset()
+	second := l.Tokens()
+
+	if len(first) != len(second) {
+		t.Fatalf("wrong number of tokens after reset. expected=%d, got=%d", len(first), len(second))
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("tokens[%d] wrong after reset. expected=%+v, got=%+v", i, first[i], second[i])
+		}
+	}
+
+	// Resetting part of the way through should also start again from the beginning.
+	l.Reset()
+	l.NextToken()
+	l.NextToken()
+	l.Reset()
+
+	if tok := l.NextToken(); tok != first[0] {
+		t.Errorf("first token wrong after partial reset. expected=%+v, got=%+v", first[0], tok)
+	}
+}
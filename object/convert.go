@@ -0,0 +1,62 @@
+package object
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FromGo converts a Go value into the equivalent Monkey object, so that it can be set in an environment. Ints and int64s
+// become integers, strings become strings, bools become booleans and slices become arrays of their converted elements.
+// An error is returned for any other type, including the elements of slices.
+func FromGo(v interface{}) (Object, error) {
+	switch v := v.(type) {
+	case int:
+		return &Integer{Value: int64(v)}, nil
+	case int64:
+		return &Integer{Value: v}, nil
+	case string:
+		return &String{Value: v}, nil
+	case bool:
+		return &Boolean{Value: v}, nil
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot convert %T to a Monkey object", v)
+	}
+
+	elements := make([]Object, value.Len())
+	for i := range elements {
+		element, err := FromGo(value.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		elements[i] = element
+	}
+
+	return &Array{Elements: elements}, nil
+}
+
+// ToGo converts a Monkey object into the equivalent Go value. Integers become int64s, strings become strings, booleans
+// become bools and arrays become []interface{} of their converted elements. Null, and any object which has no Go
+// equivalent such as a function, becomes nil.
+func ToGo(o Object) interface{} {
+	switch o := o.(type) {
+	case *Integer:
+		return o.Value
+	case *String:
+		return o.Value
+	case *Boolean:
+		return o.Value
+	case *Array:
+		elements := make([]interface{}, len(o.Elements))
+		for i, element := range o.Elements {
+			elements[i] = ToGo(element)
+		}
+
+		return elements
+	default:
+		return nil
+	}
+}
@@ -1,17 +1,25 @@
 package object
 
+import "sort"
+
 // Environment is a collection of objects associated with identifiers.
 // It holds variables.
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store     map[string]Object
+	constants map[string]bool
+	outer     *Environment
+
+	// evalState belongs to the evaluator, and holds the state of the evaluation the environment is being used in, such
+	// as the context it can be cancelled with. Extended environments share the state of their outer environment.
+	evalState interface{}
 }
 
 // NewEnvironment creates a new environment.
 func NewEnvironment() *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		outer: nil,
+		store:     make(map[string]Object),
+		constants: make(map[string]bool),
+		outer:     nil,
 	}
 }
 
@@ -33,10 +41,102 @@ func (e *Environment) Set(name string, obj Object) Object {
 	return obj
 }
 
+// SetConstant sets a value inside the environment, and marks it as a constant which cannot be changed.
+func (e *Environment) SetConstant(name string, obj Object) Object {
+	e.constants[name] = true
+	return e.Set(name, obj)
+}
+
+// IsConstant reports whether an identifier refers to a constant, looking in outer environments if the identifier isn't
+// set in this one.
+func (e *Environment) IsConstant(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.constants[name]
+	}
+
+	if e.outer != nil {
+		return e.outer.IsConstant(name)
+	}
+
+	return false
+}
+
+// IsLocalConstant reports whether an identifier refers to a constant set in this environment, ignoring outer
+// environments. A let statement can shadow a constant from an outer environment, but not rebind one in the same scope.
+func (e *Environment) IsLocalConstant(name string) bool {
+	return e.constants[name]
+}
+
+// Assign changes the value of an identifier which has already been set, in whichever environment it was set in. It
+// returns false if the identifier hasn't been set.
+func (e *Environment) Assign(name string, obj Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = obj
+		return true
+	}
+
+	if e.outer != nil {
+		return e.outer.Assign(name, obj)
+	}
+
+	return false
+}
+
+// Names returns the sorted names of every identifier visible from the environment, including those in outer
+// environments.
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of the environment which can be changed without affecting the original. The local bindings are
+// copied, but the objects they refer to are shared and the outer environment is the same as the original's.
+func (e *Environment) Clone() *Environment {
+	store := make(map[string]Object, len(e.store))
+	for name, obj := range e.store {
+		store[name] = obj
+	}
+
+	constants := make(map[string]bool, len(e.constants))
+	for name := range e.constants {
+		constants[name] = true
+	}
+
+	return &Environment{store: store, constants: constants, outer: e.outer, evalState: e.evalState}
+}
+
+// EvalState returns the evaluation state attached to the environment, or nil if there isn't any.
+func (e *Environment) EvalState() interface{} {
+	return e.evalState
+}
+
+// WithEvalState returns a view of the environment with the given evaluation state attached. The view shares its bindings
+// with the original, so setting an identifier in one sets it in both, but the original's evaluation state is unchanged.
+// This lets separate evaluations use the same environment without seeing each other's state.
+func (e *Environment) WithEvalState(state interface{}) *Environment {
+	view := *e
+	view.evalState = state
+
+	return &view
+}
+
 // NewExtendedEnvironment creates a new extended environment from an exisitng one. This is used for functions.
 func NewExtendedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.evalState = outer.evalState
 
 	return env
 }
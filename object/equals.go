@@ -0,0 +1,40 @@
+package object
+
+// Equals reports whether two objects have the same value. Integers, booleans, strings and nulls are compared by value,
+// and arrays are equal if they have the same length and their elements are equal. Any other objects are only equal if
+// they are the same object.
+func Equals(a, b Object) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value == b.(*Integer).Value
+	case *Boolean:
+		return a.Value == b.(*Boolean).Value
+	case *String:
+		return a.Value == b.(*String).Value
+	case *Null:
+		return true
+	case *Array:
+		other := b.(*Array)
+		if len(a.Elements) != len(other.Elements) {
+			return false
+		}
+
+		for i := range a.Elements {
+			if !Equals(a.Elements[i], other.Elements[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
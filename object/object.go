@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"strconv"
 	"strings"
 
 	"github.com/ollybritton/monkey/ast"
@@ -16,6 +17,9 @@ type ObjectType string
 const (
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
 	ERROR_OBJ        = "ERROR"
+	EXIT_OBJ         = "EXIT"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 
 	NULL_OBJ    = "NULL"
 	INTEGER_OBJ = "INTEGER"
@@ -26,6 +30,9 @@ const (
 
 	FUNCTION_OBJ = "FUNCTION"
 	BUILTIN_OBJ  = "BUILTIN"
+
+	QUOTE_OBJ = "QUOTE"
+	MACRO_OBJ = "MACRO"
 )
 
 // Object is an interface that represents an object inside the program. The reason this is an interface and not a struct
@@ -38,6 +45,9 @@ type Object interface {
 // BuiltinFunction is a function that is built-in to the interpreter, such as len()
 type BuiltinFunction func(args ...Object) Object
 
+// BuiltinFn is a built-in function which is also given the environment it was called from, such as env().
+type BuiltinFn func(env *Environment, args ...Object) Object
+
 // Integer represents an integer, such as "5" or "1232".
 type Integer struct {
 	Value int64
@@ -81,8 +91,11 @@ func (rv *ReturnValue) Inspect() string { return rv.Value.Inspect() }
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
 // Error represents an error that occurs.
+// Errors made by the `error` builtin have Constructed set. Unlike other errors, they don't stop evaluation, so they can
+// be stored and inspected like any other value until they are returned from a function.
 type Error struct {
-	Message string
+	Message     string
+	Constructed bool
 }
 
 // Inspect gets the error message.
@@ -91,9 +104,40 @@ func (e *Error) Inspect() string { return "error: " + e.Message }
 // Type gets the ERROR_OBJ type.
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
+// Exit represents a request to stop the program with the given status code. It is passed up through the evaluator like
+// an error, so that whatever is running the program can decide how to exit.
+type Exit struct {
+	Code int64
+}
+
+// Inspect gets a description of the exit.
+func (e *Exit) Inspect() string { return fmt.Sprintf("exit(%d)", e.Code) }
+
+// Type gets the EXIT_OBJ type.
+func (e *Exit) Type() ObjectType { return EXIT_OBJ }
+
+// Break is produced by a break statement, and is passed up to the loop it is inside to stop it.
+type Break struct{}
+
+// Inspect gets the string "break".
+func (b *Break) Inspect() string { return "break" }
+
+// Type gets the BREAK_OBJ type.
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+
+// Continue is produced by a continue statement, and is passed up to the loop it is inside to skip to the next iteration.
+type Continue struct{}
+
+// Inspect gets the string "continue".
+func (c *Continue) Inspect() string { return "continue" }
+
+// Type gets the CONTINUE_OBJ type.
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+
 // Function represents a function that is being evaluated.
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -105,16 +149,52 @@ func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
+	out.WriteString("fn")
+	out.WriteString("(")
+	out.WriteString(ast.ParametersString(f.Parameters, f.Defaults))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+// Quote wraps an unevaluated AST node, and is produced by calling quote().
+type Quote struct {
+	Node ast.Node
+}
+
+// Type gets the QUOTE_OBJ type.
+func (q *Quote) Type() ObjectType { return QUOTE_OBJ }
+
+// Inspect gets the quoted node as a string.
+func (q *Quote) Inspect() string { return "QUOTE(" + q.Node.String() + ")" }
+
+// Macro represents a macro defined in the program. Like a function, it has parameters, a body and the environment it
+// was defined in.
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+// Type gets the MACRO_OBJ type.
+func (m *Macro) Type() ObjectType { return MACRO_OBJ }
+
+// Inspect gets the definition of the macro as a string.
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
 	params := []string{}
-	for _, p := range f.Parameters {
+	for _, p := range m.Parameters {
 		params = append(params, p.String())
 	}
 
-	out.WriteString("fn")
+	out.WriteString("macro")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(") {\n")
-	out.WriteString(f.Body.String())
+	out.WriteString(m.Body.String())
 	out.WriteString("\n}")
 
 	return out.String()
@@ -142,7 +222,7 @@ func (a *Array) Inspect() string {
 	var elements []string
 
 	for _, e := range a.Elements {
-		elements = append(elements, e.Inspect())
+		elements = append(elements, inspectElement(e))
 	}
 
 	out.WriteString("[")
@@ -152,9 +232,30 @@ func (a *Array) Inspect() string {
 	return out.String()
 }
 
+// inspectElement returns the string representation of an object inside an array or a hash. Strings are quoted so that
+// ["a"] can be told apart from [a], but are left unquoted by String.Inspect so that puts prints them as they are.
+func inspectElement(o Object) string {
+	if s, ok := o.(*String); ok {
+		return strconv.Quote(s.Value)
+	}
+
+	return o.Inspect()
+}
+
 // Builtin wraps a built-in function so that it is usable inside the program.
+// Builtins which need the environment they are called from, such as `env`, set EnvFn instead of Fn.
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn    BuiltinFunction
+	EnvFn BuiltinFn
+}
+
+// Call calls the builtin with the environment it was called from. Builtins which only set Fn are called without it.
+func (b *Builtin) Call(env *Environment, args ...Object) Object {
+	if b.EnvFn != nil {
+		return b.EnvFn(env, args...)
+	}
+
+	return b.Fn(args...)
 }
 
 // Type returns the BUILTIN_OBJ type.
@@ -167,6 +268,8 @@ type Hashable interface {
 	HashKey() HashKey
 }
 
+// HashKey is the key used to store an object in a hash. It includes the object's type, so that objects of different
+// types never collide even if their hashed values are the same, such as 1 and "1" or 1 and true.
 type HashKey struct {
 	Type  ObjectType
 	Value uint64
@@ -214,7 +317,7 @@ func (h *Hash) Inspect() string {
 
 	pairs := []string{}
 	for _, pair := range h.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
+		pairs = append(pairs, fmt.Sprintf("%s: %s", inspectElement(pair.Key), inspectElement(pair.Value)))
 	}
 
 	out.WriteString("{")
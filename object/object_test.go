@@ -1,6 +1,9 @@
 package object
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -20,3 +23,285 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("strings with different content have same hash keys")
 	}
 }
+
+func TestEquals(t *testing.T) {
+	tests := []struct {
+		a, b     Object
+		expected bool
+	}{
+		{&Integer{Value: 5}, &Integer{Value: 5}, true},
+		{&Integer{Value: 5}, &Integer{Value: 6}, false},
+		{&Boolean{Value: true}, &Boolean{Value: true}, true},
+		{&Boolean{Value: true}, &Boolean{Value: false}, false},
+		{&String{Value: "a"}, &String{Value: "a"}, true},
+		{&String{Value: "a"}, &String{Value: "b"}, false},
+		{&Null{}, &Null{}, true},
+		{&Integer{Value: 1}, &String{Value: "1"}, false},
+		{
+			&Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}},
+			&Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}},
+			true,
+		},
+		{
+			&Array{Elements: []Object{&Integer{Value: 1}}},
+			&Array{Elements: []Object{&Integer{Value: 2}}},
+			false,
+		},
+		{
+			&Array{Elements: []Object{&Integer{Value: 1}}},
+			&Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}}},
+			false,
+		},
+		{
+			&Array{Elements: []Object{&Array{Elements: []Object{&Integer{Value: 1}}}}},
+			&Array{Elements: []Object{&Array{Elements: []Object{&Integer{Value: 1}}}}},
+			true,
+		},
+		{&Hash{}, &Hash{}, false},
+	}
+
+	for _, tt := range tests {
+		if Equals(tt.a, tt.b) != tt.expected {
+			t.Errorf("Equals(%s, %s) wrong. want=%t", tt.a.Inspect(), tt.b.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("b", &Integer{Value: 1})
+	outer.Set("a", &Integer{Value: 2})
+
+	inner := NewExtendedEnvironment(outer)
+	inner.Set("c", &Integer{Value: 3})
+	inner.Set("a", &Integer{Value: 4})
+
+	names := inner.Names()
+	expected := []string{"a", "b", "c"}
+
+	if len(names) != len(expected) {
+		t.Fatalf("wrong number of names. got=%v, want=%v", names, expected)
+	}
+
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("names[%d] wrong. got=%q, want=%q", i, names[i], name)
+		}
+	}
+
+	if len(NewEnvironment().Names()) != 0 {
+		t.Errorf("empty environment has names")
+	}
+}
+
+func TestEnvironmentClone(t *testing.T) {
+	outer := NewEnvironment()
+	original := NewExtendedEnvironment(outer)
+	original.Set("a", &Integer{Value: 1})
+
+	clone := original.Clone()
+	clone.Set("a", &Integer{Value: 2})
+	clone.Set("b", &Integer{Value: 3})
+
+	a, _ := original.Get("a")
+	if a.(*Integer).Value != 1 {
+		t.Errorf("changing the clone changed the original. got a=%d", a.(*Integer).Value)
+	}
+
+	if _, ok := original.Get("b"); ok {
+		t.Errorf("binding added to clone is visible in the original")
+	}
+
+	a, _ = clone.Get("a")
+	if a.(*Integer).Value != 2 {
+		t.Errorf("clone has wrong value. got a=%d", a.(*Integer).Value)
+	}
+
+	outer.Set("c", &Integer{Value: 4})
+	if _, ok := clone.Get("c"); !ok {
+		t.Errorf("clone does not share the outer environment")
+	}
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("a", &Integer{Value: 1})
+	inner := NewExtendedEnvironment(outer)
+
+	if !inner.Assign("a", &Integer{Value: 2}) {
+		t.Fatalf("could not assign to identifier in outer environment")
+	}
+
+	a, _ := outer.Get("a")
+	if a.(*Integer).Value != 2 {
+		t.Errorf("assignment did not change the outer environment. got a=%d", a.(*Integer).Value)
+	}
+
+	if inner.Assign("b", &Integer{Value: 3}) {
+		t.Errorf("assigned to an identifier which was never set")
+	}
+}
+
+func TestEnvironmentConstants(t *testing.T) {
+	outer := NewEnvironment()
+	outer.SetConstant("a", &Integer{Value: 1})
+	outer.Set("b", &Integer{Value: 2})
+	inner := NewExtendedEnvironment(outer)
+
+	if !inner.IsConstant("a") {
+		t.Errorf("a should be constant when looked up from an inner environment")
+	}
+
+	if inner.IsConstant("b") || inner.IsConstant("c") {
+		t.Errorf("only a should be constant")
+	}
+
+	if inner.IsLocalConstant("a") || !outer.IsLocalConstant("a") {
+		t.Errorf("a should only be a local constant in the outer environment")
+	}
+
+	inner.Set("a", &Integer{Value: 3})
+	if inner.IsConstant("a") {
+		t.Errorf("a shadowed by a non-constant in the inner environment should not be constant")
+	}
+
+	if !outer.Clone().IsConstant("a") {
+		t.Errorf("clone did not keep a as a constant")
+	}
+}
+
+func TestHashKeyIncludesType(t *testing.T) {
+	keys := []Hashable{
+		&Integer{Value: 1},
+		&String{Value: "1"},
+		&Boolean{Value: true},
+	}
+
+	for i, a := range keys {
+		for j, b := range keys {
+			if i != j && a.HashKey() == b.HashKey() {
+				t.Errorf("%s and %s have the same hash key", a.(Object).Inspect(), b.(Object).Inspect())
+			}
+		}
+	}
+}
+
+func TestInspectCollections(t *testing.T) {
+	tests := []struct {
+		obj      Object
+		expected string
+	}{
+		{&String{Value: "a"}, "a"},
+		{
+			&Array{Elements: []Object{
+				&Integer{Value: 1},
+				&String{Value: "a"},
+				&Array{Elements: []Object{&Integer{Value: 2}, &String{Value: "b"}}},
+			}},
+			`[1, "a", [2, "b"]]`,
+		},
+		{&Array{Elements: []Object{&String{Value: `say "hi"`}}}, `["say \"hi\""]`},
+		{
+			&Hash{Pairs: map[HashKey]HashPair{
+				(&String{Value: "k"}).HashKey(): {Key: &String{Value: "k"}, Value: &String{Value: "v"}},
+			}},
+			`{"k": "v"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.obj.Inspect() != tt.expected {
+			t.Errorf("Inspect() wrong. got=%q, want=%q", tt.obj.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestFromGoToGo(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected Object
+		back     interface{}
+	}{
+		{5, &Integer{Value: 5}, int64(5)},
+		{int64(-7), &Integer{Value: -7}, int64(-7)},
+		{"hello", &String{Value: "hello"}, "hello"},
+		{true, &Boolean{Value: true}, true},
+		{false, &Boolean{Value: false}, false},
+		{
+			[]int{1, 2},
+			&Array{Elements: []Object{&Integer{Value: 1}, &Integer{Value: 2}}},
+			[]interface{}{int64(1), int64(2)},
+		},
+		{
+			[]interface{}{"a", []string{"b"}, true},
+			&Array{Elements: []Object{
+				&String{Value: "a"},
+				&Array{Elements: []Object{&String{Value: "b"}}},
+				&Boolean{Value: true},
+			}},
+			[]interface{}{"a", []interface{}{"b"}, true},
+		},
+		{[]string{}, &Array{Elements: []Object{}}, []interface{}{}},
+	}
+
+	for _, tt := range tests {
+		obj, err := FromGo(tt.input)
+		if err != nil {
+			t.Errorf("FromGo(%#v) returned error: %s", tt.input, err)
+			continue
+		}
+
+		if obj.Type() != tt.expected.Type() || obj.Inspect() != tt.expected.Inspect() {
+			t.Errorf("FromGo(%#v) wrong. got=%s, want=%s", tt.input, obj.Inspect(), tt.expected.Inspect())
+		}
+
+		if back := ToGo(obj); !reflect.DeepEqual(back, tt.back) {
+			t.Errorf("ToGo(FromGo(%#v)) wrong. got=%#v, want=%#v", tt.input, back, tt.back)
+		}
+	}
+}
+
+func TestFromGoUnsupported(t *testing.T) {
+	inputs := []interface{}{
+		1.5,
+		nil,
+		map[string]int{"a": 1},
+		[]float64{1.5},
+		struct{}{},
+	}
+
+	for _, input := range inputs {
+		if obj, err := FromGo(input); err == nil {
+			t.Errorf("FromGo(%#v) should return an error. got=%v", input, obj)
+		}
+	}
+}
+
+func TestToGoUnsupported(t *testing.T) {
+	if v := ToGo(&Null{}); v != nil {
+		t.Errorf("ToGo(null) should be nil. got=%#v", v)
+	}
+
+	if v := ToGo(&Function{}); v != nil {
+		t.Errorf("ToGo(function) should be nil. got=%#v", v)
+	}
+}
+
+func TestBuiltinCall(t *testing.T) {
+	env := NewEnvironment()
+	env.Set("x", &Integer{Value: 5})
+
+	plain := &Builtin{Fn: func(args ...Object) Object { return &Integer{Value: int64(len(args))} }}
+	if result, ok := plain.Call(env, &Integer{Value: 1}, &Integer{Value: 2}).(*Integer); !ok || result.Value != 2 {
+		t.Errorf("plain builtin called wrongly. got=%+v", result)
+	}
+
+	withEnv := &Builtin{EnvFn: func(env *Environment, args ...Object) Object {
+		value, _ := env.Get("x")
+		return value
+	}}
+
+	if result, ok := withEnv.Call(env).(*Integer); !ok || result.Value != 5 {
+		t.Errorf("environment builtin called wrongly. got=%+v", result)
+	}
+}
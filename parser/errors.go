@@ -0,0 +1,19 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseErrors is the error returned by Parse when the program could not be parsed. It holds every error message, in the
+// order they were found.
+type ParseErrors []string
+
+// Error returns the error messages joined together into a single message.
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0]
+	}
+
+	return fmt.Sprintf("%d parse errors: %s", len(e), strings.Join(e, "; "))
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/ollybritton/monkey/ast"
 	"github.com/ollybritton/monkey/lexer"
@@ -13,28 +14,75 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // a = b
+	TERNARY     // a ? b : c
 	EQUALS      // ==, !=
 	LESSGREATER // <, >
 	SUM         // +, -
 	PRODUCT     // *, /
+	POWER       // **
 	PREFIX      // -x, !true
+	POSTFIX     // i++
 	CALL        // sum(1,2)
 	INDEX       // array[index]
 )
 
 // Maps token types to precendences.
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.QUESTION:        TERNARY,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.POWER:           POWER,
+
+	token.INCREMENT: POSTFIX,
+	token.DECREMENT: POSTFIX,
 
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX,
+	token.DOT:      INDEX,
+}
+
+// rightAssociative holds the infix operators which group from the right, so "a ** b ** c" is "a ** (b ** c)" and
+// "a = b = c" is "a = (b = c)". Every other operator groups from the left.
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER:           true,
+	token.ASSIGN:          true,
+	token.PLUS_ASSIGN:     true,
+	token.MINUS_ASSIGN:    true,
+	token.ASTERISK_ASSIGN: true,
+	token.SLASH_ASSIGN:    true,
+}
+
+// startsOperand holds the tokens which can only start an operand, and so can't directly follow a postfix "++" or "--".
+// Without this, "x--y" would be read as "x--" followed by a separate statement "y" rather than being an error.
+var startsOperand = map[token.TokenType]bool{
+	token.IDENT:    true,
+	token.INT:      true,
+	token.STRING:   true,
+	token.TRUE:     true,
+	token.FALSE:    true,
+	token.NULL:     true,
+	token.BANG:     true,
+	token.LPAREN:   true,
+	token.LBRACKET: true,
+	token.FUNCTION: true,
+	token.MACRO:    true,
+	token.IF:       true,
+	token.SWITCH:   true,
+	token.TRY:      true,
 }
 
 type (
@@ -51,6 +99,10 @@ type Parser struct {
 
 	errors []string
 
+	// panicking is set when an error is found, and cleared once the parser has skipped to the end of the statement the
+	// error was in. Errors found in the meantime are usually caused by the first one, so they are not reported.
+	panicking bool
+
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
@@ -74,13 +126,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
-
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseCompoundAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseCompoundAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseCompoundAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseCompoundAssignExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixExpression)
+	p.registerInfix(token.QUESTION, p.parseTernaryExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
@@ -89,8 +153,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNullLiteral)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.SWITCH, p.parseSwitchExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
@@ -109,16 +177,48 @@ func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+// addError records an error, unless the parser is still recovering from an earlier error in the same statement.
+func (p *Parser) addError(msg string) {
+	if !p.panicking {
+		p.errors = append(p.errors, msg)
+	}
+
+	p.panicking = true
+}
+
+// synchronize recovers from an error by skipping tokens until curToken is the semicolon at the end of the statement, or
+// peekToken is the closing brace of the enclosing block. Braces opened while skipping are matched, so a statement such as
+// "let = fn() { a; b };" is skipped in full.
+func (p *Parser) synchronize() {
+	p.panicking = false
+	depth := 0
+
+	for !p.curTokenIs(token.EOF) && !p.peekTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			depth--
+		}
+
+		if depth <= 0 && (p.curTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE)) {
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
 // peekError creates a new error that says that the peeked token was expected to be something else.
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
 }
 
 // noPrefixParseFnError creates a new error that says it cannot find a prefix parse function for the given token type.
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(msg)
 }
 
 // nextToken gets the next token from the lexer.
@@ -134,7 +234,9 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 	for p.curToken.Type != token.EOF {
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if p.panicking {
+			p.synchronize()
+		} else if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 
@@ -144,13 +246,37 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// Parse parses the program like ParseProgram, but also returns a ParseErrors error if there were any errors while
+// parsing. The program is still returned when there are errors, containing the statements which could be parsed.
+func (p *Parser) Parse() (*ast.Program, error) {
+	program := p.ParseProgram()
+
+	if len(p.errors) != 0 {
+		return program, append(ParseErrors{}, p.errors...)
+	}
+
+	return program, nil
+}
+
 // parseStatement parses a single statement into an ast.Statement.
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.FOR:
+		if p.peekTokenIs(token.IDENT) {
+			return p.parseForInStatement()
+		}
+
+		return p.parseForStatement()
+	case token.DO:
+		return p.parseDoWhileStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -185,7 +311,8 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 
 	stmt.Expression = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
+	// After an error, curToken may already be the closing brace of a block, so the semicolon after it is left alone.
+	if !p.panicking && p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -202,13 +329,13 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
-// parseIntegerLiteral parses an integer into an ast.Expression.
+// parseIntegerLiteral parses an integer into an ast.Expression. Any underscores separating the digits are ignored.
 func (p *Parser) parseIntegerLiteral() ast.Expression {
-	val, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	val, err := strconv.ParseInt(strings.ReplaceAll(p.curToken.Literal, "_", ""), 0, 64)
 
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(msg)
 		return nil
 	}
 
@@ -225,7 +352,13 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
-// parsePrefixExpression parses a prefix expression.
+// parseNullLiteral parses the null keyword.
+func (p *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: p.curToken}
+}
+
+// parsePrefixExpression parses a prefix expression. A minus in front of an integer literal is folded into the literal,
+// so "-5" is parsed as the integer literal -5 rather than as a prefix expression.
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	var pe = &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -235,6 +368,18 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 	p.nextToken()
 	pe.Right = p.parseExpression(PREFIX)
 
+	if integer, ok := pe.Right.(*ast.IntegerLiteral); ok && pe.Operator == "-" {
+		return &ast.IntegerLiteral{
+			Token: token.Token{
+				Type:    token.INT,
+				Literal: "-" + integer.Token.Literal,
+				Pos:     pe.Token.Pos,
+				End:     integer.Token.End,
+			},
+			Value: -integer.Value,
+		}
+	}
+
 	return pe
 }
 
@@ -282,7 +427,34 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameters()
+	lit.Parameters, lit.Defaults = p.parseFunctionParameters()
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
+// parseMacroLiteral parses a macro into an ast.MacroLiteral.
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	lit := &ast.MacroLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	var defaults []ast.Expression
+	lit.Parameters, defaults = p.parseFunctionParameters()
+
+	for _, d := range defaults {
+		if d != nil {
+			p.addError("macro parameters cannot have default values")
+			return nil
+		}
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -299,6 +471,8 @@ func (p *Parser) parseArrayLiterals() ast.Expression {
 	return array
 }
 
+// parseExpressionList parses a comma separated list of expressions, finishing at the end token. A trailing comma
+// before the end token is allowed.
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	expressions := []ast.Expression{}
 
@@ -312,6 +486,11 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+
+		if p.peekTokenIs(end) {
+			break
+		}
+
 		p.nextToken()
 		expressions = append(expressions, p.parseExpression(LOWEST))
 	}
@@ -323,11 +502,54 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return expressions
 }
 
+// parseIndexExpression parses an index expression, such as arr[1], or a slice expression, such as arr[1:3].
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
 
-	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index}
+}
+
+// parseMemberExpression parses a member access, such as h.name. There is no separate node for it: it is desugared into an
+// index expression with a string key, so h.name is the same as h["name"].
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	key := &ast.StringLiteral{
+		Token: token.Token{Type: token.STRING, Literal: p.curToken.Literal, Pos: p.curToken.Pos, End: p.curToken.End},
+		Value: p.curToken.Literal,
+	}
+
+	return &ast.IndexExpression{Token: tok, Left: left, Index: key}
+}
+
+// parseSliceExpression parses the rest of a slice expression, starting at the ':' token. Low is the lower bound that
+// has already been parsed, or nil if it was omitted.
+func (p *Parser) parseSliceExpression(tok token.Token, left, low ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Low: low}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.High = p.parseExpression(LOWEST)
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
@@ -336,33 +558,51 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
-// parseFunctionParameters parses a function's parameters.
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+// parseFunctionParameters parses a function's parameters, along with the default value for each one. Parameters without
+// a default value, such as x in "fn(x, y = 10)", have a nil default. A trailing comma after the last parameter is
+// allowed.
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, []ast.Expression) {
 	identifiers := []*ast.Identifier{}
+	defaults := []ast.Expression{}
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return identifiers, defaults
 	}
 
-	p.nextToken()
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil, nil
+		}
 
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifiers = append(identifiers, ident)
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+		identifiers = append(identifiers, ident)
+
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			def = p.parseExpression(LOWEST)
+		}
+
+		defaults = append(defaults, def)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
 		p.nextToken()
 
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-		identifiers = append(identifiers, ident)
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, nil
 	}
 
-	return identifiers
+	return identifiers, defaults
 }
 
 // parseCallExpression parses a function call into an ast.CallExpression.
@@ -370,38 +610,173 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	var ce = &ast.CallExpression{Token: p.curToken}
 
 	ce.Function = function
-	ce.Arguments = p.parseCallArguments()
+	ce.Arguments = p.parseExpressionList(token.RPAREN)
 
 	return ce
 }
 
-// parseCallArguments parses a list of function call arguments.
-func (p *Parser) parseCallArguments() []ast.Expression {
-	arguments := []ast.Expression{}
+// parseSwitchExpression parses a switch expression, such as "switch (x) { case 1: a; default: b }".
+func (p *Parser) parseSwitchExpression() ast.Expression {
+	expression := &ast.SwitchExpression{Token: p.curToken}
 
-	if p.peekTokenIs(token.RPAREN) {
-		p.nextToken()
-		return arguments
+	if !p.expectPeek(token.LPAREN) {
+		return nil
 	}
 
 	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
 
-	arg := p.parseExpression(LOWEST)
-	arguments = append(arguments, arg)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.nextToken()
+
+	if !p.parseSwitchCases(expression) {
+		// The opening brace has already been read, so skip to the end of the switch to leave the parser where it would
+		// be if the error had been found before it.
+		p.skipToClosingBrace()
+		return nil
+	}
+
+	if p.curTokenIs(token.EOF) {
+		p.addError("expected }, got EOF")
+	}
+
+	return expression
+}
+
+// parseSwitchCases parses the cases in the body of a switch expression, adding them to the expression. It returns false
+// if there is an error.
+func (p *Parser) parseSwitchCases(expression *ast.SwitchExpression) bool {
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.CASE:
+			c := &ast.SwitchCase{Token: p.curToken}
+
+			p.nextToken()
+			c.Values = append(c.Values, p.parseExpression(LOWEST))
+
+			for p.peekTokenIs(token.COMMA) {
+				p.nextToken()
+				p.nextToken()
+				c.Values = append(c.Values, p.parseExpression(LOWEST))
+			}
+
+			if !p.expectPeek(token.COLON) {
+				return false
+			}
+
+			c.Body = p.parseCaseBody()
+			expression.Cases = append(expression.Cases, c)
+		case token.DEFAULT:
+			if expression.Default != nil {
+				p.addError("switch has more than one default case")
+				return false
+			}
+
+			if !p.expectPeek(token.COLON) {
+				return false
+			}
+
+			expression.Default = p.parseCaseBody()
+		default:
+			p.addError(fmt.Sprintf("expected case or default in switch, got %s", p.curToken.Type))
+			return false
+		}
+	}
+
+	return true
+}
+
+// skipToClosingBrace skips tokens until curToken is the closing brace of the block the parser is in, matching any braces
+// opened along the way.
+func (p *Parser) skipToClosingBrace() {
+	depth := 0
+
+	for !p.curTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth == 0 {
+				return
+			}
+
+			depth--
+		}
 
-	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+	}
+}
+
+// parseCaseBody parses the statements after the ':' of a case in a switch expression, up to the next case, the default
+// case or the end of the switch. They are returned as a block, which uses the ':' token since there is no '{'.
+func (p *Parser) parseCaseBody() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.CASE) && !p.curTokenIs(token.DEFAULT) && !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		stmt := p.parseStatement()
+
+		// An error such as "case 1: x + }" leaves curToken on the closing brace, so the switch has already ended.
+		if p.panicking && p.curTokenIs(token.RBRACE) {
+			p.panicking = false
+			break
+		}
+
+		if p.panicking {
+			p.synchronize()
+		} else if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+
 		p.nextToken()
+	}
+
+	return block
+}
+
+// parseTryExpression parses a try/catch, such as "try { risky() } catch (e) { puts(e) }".
+func (p *Parser) parseTryExpression() ast.Expression {
+	expression := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	if !p.expectPeek(token.CATCH) {
+		return nil
+	}
 
-		arg := p.parseExpression(LOWEST)
-		arguments = append(arguments, arg)
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
 	}
 
+	expression.Parameter = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return arguments
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Catch = p.parseBlockStatement()
+
+	return expression
 }
 
 // parseBlockStatement parses a block statement into a ast.BlockStatement
@@ -414,13 +789,26 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
 		stmt := p.parseStatement()
-		if stmt != nil {
+
+		// An error such as "{ x + }" leaves curToken on the closing brace, so the block has already ended.
+		if p.panicking && p.curTokenIs(token.RBRACE) {
+			p.panicking = false
+			break
+		}
+
+		if p.panicking {
+			p.synchronize()
+		} else if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
 
 		p.nextToken()
 	}
 
+	if p.curTokenIs(token.EOF) {
+		p.addError("expected }, got EOF")
+	}
+
 	return block
 }
 
@@ -432,13 +820,102 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Operator: p.curToken.Literal,
 	}
 
-	precedence := p.curPrecedence()
+	precedence := p.rightPrecedence()
+
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+// rightPrecedence returns the precedence to parse the right hand side of the current infix operator with. For a
+// right-associative operator this is one lower than its own precedence, so that the right hand side can contain the same
+// operator again.
+func (p *Parser) rightPrecedence() int {
+	precedence := p.curPrecedence()
+	if rightAssociative[p.curToken.Type] {
+		precedence--
+	}
+
+	return precedence
+}
+
+// parseAssignExpression parses an assignment, like "a = 5". Assignment is right-associative, so "a = b = 5" assigns 5
+// to b and then to a.
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:  p.curToken,
+		Target: target,
+	}
+
+	precedence := p.rightPrecedence()
+
+	p.nextToken()
+	expression.Value = p.parseExpression(precedence)
+
+	return expression
+}
+
+// parseCompoundAssignExpression parses an assignment combined with an arithmetic operator, like "a += 5". It isn't
+// desugared into "a = a + 5", so that a target like "a[f()]" is only evaluated once.
+func (p *Parser) parseCompoundAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.CompoundAssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: strings.TrimSuffix(p.curToken.Literal, "="),
+	}
+
+	precedence := p.rightPrecedence()
+
+	p.nextToken()
+	expression.Value = p.parseExpression(precedence)
+
+	return expression
+}
+
+// parsePostfixExpression parses an increment or decrement, like "i++" or "i--". Only identifiers can be incremented or
+// decremented. The lexer only reads "++" and "--" as operators after an operand, so "--5" is still parsed as "-(-5)".
+// An increment or decrement can't be directly followed by another operand, so "x--y" is an error rather than "x--; y".
+func (p *Parser) parsePostfixExpression(target ast.Expression) ast.Expression {
+	ident, ok := target.(*ast.Identifier)
+	if !ok {
+		p.addError(fmt.Sprintf("%s can only be applied to an identifier, got %s", p.curToken.Literal, target.String()))
+		return nil
+	}
+
+	if startsOperand[p.peekToken.Type] {
+		p.addError(fmt.Sprintf("unexpected %s after %s%s", p.peekToken.Literal, ident.Value, p.curToken.Literal))
+		return nil
+	}
+
+	return &ast.PostfixExpression{
+		Token:    p.curToken,
+		Target:   ident,
+		Operator: p.curToken.Literal,
+	}
+}
+
+// parseTernaryExpression parses a ternary expression, like "a ? b : c". The alternative is parsed with a lower
+// precedence than the ternary itself, so that "a ? b : c ? d : e" is parsed as "a ? b : (c ? d : e)".
+func (p *Parser) parseTernaryExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.TernaryExpression{
+		Token:     p.curToken,
+		Condition: condition,
+	}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+
+	return expression
+}
+
 // parseGroupedExpression parses and expression involving brackets.
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	p.nextToken()
@@ -451,16 +928,25 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 	return exp
 }
 
-// parseLetStatment parses a let statement into an ast.LetStatement.
+// parseLetStatment parses a let or const statement into an ast.LetStatement.
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	stmt := &ast.LetStatement{Token: p.curToken}
+	stmt := &ast.LetStatement{Token: p.curToken, Constant: p.curTokenIs(token.CONST)}
 
-	// Check that the next token is an identifier, and move it to p.curToken if it is.
-	if !p.expectPeek(token.IDENT) {
-		return nil
-	}
+	if p.peekTokenIs(token.LBRACKET) {
+		p.nextToken()
 
-	stmt.Name = &ast.Identifier{Value: p.curToken.Literal, Token: p.curToken}
+		stmt.Pattern = p.parseArrayPattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		// Check that the next token is an identifier, and move it to p.curToken if it is.
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Value: p.curToken.Literal, Token: p.curToken}
+	}
 
 	// Check that the next token is an assignment (=), and move to p.curToken if it is.
 	if !p.expectPeek(token.ASSIGN) {
@@ -471,13 +957,43 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
-	if p.peekTokenIs(token.SEMICOLON) {
+	if !p.panicking && p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
 	return stmt
 }
 
+// parseArrayPattern parses the list of names being destructured in a let statement, such as "[a, b, c]".
+func (p *Parser) parseArrayPattern() *ast.ArrayPattern {
+	pattern := &ast.ArrayPattern{Token: p.curToken, Elements: []*ast.Identifier{}}
+
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return pattern
+	}
+
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		pattern.Elements = append(pattern.Elements, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
 // parseReturnStatement parsers a return statement into an ast.ReturnStatment.
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
@@ -486,6 +1002,153 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
+	if !p.panicking && p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseForStatement parses a C-style for loop into an ast.ForStatement. The init statement, condition and post
+// expression are all optional, but the semicolons separating them are not.
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	// Parsing the init statement might consume the semicolon after it, so only expect one if it hasn't.
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Init = p.parseStatement()
+
+		if !p.curTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	if !p.curTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.SEMICOLON) {
+			return nil
+		}
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		stmt.Post = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseDoWhileStatement parses a loop which checks its condition after running its body into an ast.DoWhileStatement.
+func (p *Parser) parseDoWhileStatement() *ast.DoWhileStatement {
+	stmt := &ast.DoWhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if !p.expectPeek(token.WHILE) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseForInStatement parses a loop over the items in a collection into an ast.ForInStatement.
+func (p *Parser) parseForInStatement() *ast.ForInStatement {
+	stmt := &ast.ForInStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseBreakStatement parses a break statement into an ast.BreakStatement.
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseContinueStatement parses a continue statement into an ast.ContinueStatement.
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -493,7 +1156,7 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
-// parseHashLiteral parses a hashmap into an ast.HashLiteral.
+// parseHashLiteral parses a hashmap into an ast.HashLiteral. A trailing comma after the last pair is allowed.
 func (p *Parser) parseHashLiteral() ast.Expression {
 	hash := &ast.HashLiteral{Token: p.curToken}
 	hash.Pairs = make(map[ast.Expression]ast.Expression)
@@ -59,6 +59,41 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedConstant bool
+		expectedString   string
+	}{
+		{"const x = 5;", true, "const x = 5;"},
+		{"let x = 5;", false, "let x = 5;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Constant != tt.expectedConstant {
+			t.Errorf("stmt.Constant wrong. got=%t, want=%t", stmt.Constant, tt.expectedConstant)
+		}
+
+		if stmt.String() != tt.expectedString {
+			t.Errorf("stmt.String() wrong. got=%q, want=%q", stmt.String(), tt.expectedString)
+		}
+	}
+}
+
 func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "let" {
 		t.Errorf("s.TokenLiteral not 'let'. got=%q", s.TokenLiteral())
@@ -85,6 +120,60 @@ func testLetStatement(t *testing.T, s ast.Statement, name string) bool {
 	return true
 }
 
+func TestDestructuringLetStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedNames []string
+		expected      string
+	}{
+		{"let [a, b, c] = [1, 2, 3];", []string{"a", "b", "c"}, "let [a, b, c] = [1, 2, 3];"},
+		{"let [x] = pair;", []string{"x"}, "let [x] = pair;"},
+		{"let [] = [];", []string{}, "let [] = [];"},
+		{"const [k, v] = f();", []string{"k", "v"}, "const [k, v] = f();"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("stmt not *ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Name != nil {
+			t.Errorf("stmt.Name is not nil. got=%+v", stmt.Name)
+		}
+
+		if stmt.Pattern == nil {
+			t.Fatalf("stmt.Pattern is nil")
+		}
+
+		if len(stmt.Pattern.Elements) != len(tt.expectedNames) {
+			t.Fatalf("wrong number of names. want=%d, got=%d", len(tt.expectedNames), len(stmt.Pattern.Elements))
+		}
+
+		for i, name := range tt.expectedNames {
+			testIdentifier(t, stmt.Pattern.Elements[i], name)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong string for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"let [a, 1] = x;", "let [a b] = x;", "let [a, b = x;"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected errors for %q, got none", input)
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	input := `
 	return 5;
@@ -177,6 +266,48 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralFormats(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1_000_000;", 1000000},
+		{"1_0;", 10},
+		{"123;", 123},
+		{"0xFF;", 255},
+		{"0b1010;", 10},
+		{"0o17;", 15},
+		{"0x1_0;", 16},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+	}
+
+	for _, input := range []string{"1__000;", "1000_;"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestStringLiteralExpression(t *testing.T) {
 	input := `"hello world";`
 
@@ -196,6 +327,25 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNullLiteralExpression(t *testing.T) {
+	input := "null;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.NullLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.NullLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.TokenLiteral() != "null" {
+		t.Errorf("literal.TokenLiteral not %q. got=%q", "null", literal.TokenLiteral())
+	}
+}
+
 func TestIfExpression(t *testing.T) {
 	input := `if (x < y) { x }`
 
@@ -296,7 +446,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 		value    interface{}
 	}{
 		{"!5;", "!", 5},
-		{"-15;", "-", 15},
+		{"-a;", "-", "a"},
 		{"!true", "!", true},
 		{"!false", "!", false},
 	}
@@ -331,6 +481,69 @@ func TestParsingPrefixExpressions(t *testing.T) {
 	}
 }
 
+func TestNegativeIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedValue int64
+		expected      string
+	}{
+		{"-5", -5, "-5"},
+		{"--5", 5, "--5"},
+		{"- -5", 5, "--5"},
+		{"-0x10", -16, "-0x10"},
+		{"-1_000", -1000, "-1_000"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		integer, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("%q: exp is not *ast.IntegerLiteral. got=%T", tt.input, stmt.Expression)
+		}
+
+		if integer.Value != tt.expectedValue {
+			t.Errorf("%q: integer.Value wrong. got=%d, want=%d", tt.input, integer.Value, tt.expectedValue)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("%q: program.String() wrong. got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+
+		reparsed := New(lexer.New(program.String())).ParseProgram()
+		if reparsed.String() != tt.expected {
+			t.Errorf("%q: program.String() did not re-parse. got=%q, want=%q", tt.input, reparsed.String(), tt.expected)
+		}
+	}
+
+	prefixTests := []struct {
+		input    string
+		expected string
+	}{
+		{"-x", "(-x)"},
+		{"-(5 + 1)", "(-(5 + 1))"},
+		{"-a[0]", "(-(a[0]))"},
+		{"-5 ** 2", "(-5 ** 2)"},
+		{"!-5", "(!-5)"},
+	}
+
+	for _, tt := range prefixTests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("%q: program.String() wrong. got=%q, want=%q", tt.input, program.String(), tt.expected)
+		}
+	}
+}
+
 func TestParsingInfixExpressions(t *testing.T) {
 	infixTests := []struct {
 		input      string
@@ -344,6 +557,8 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 / 5", 5, "/", 5},
 		{"5 > 5", 5, ">", 5},
 		{"5 < 5", 5, "<", 5},
+		{"5 <= 5", 5, "<=", 5},
+		{"5 >= 5", 5, ">=", 5},
 		{"5 == 5", 5, "==", 5},
 		{"5 != 5", 5, "!=", 5},
 		{"true == true", true, "==", true},
@@ -381,6 +596,118 @@ func TestParsingInfixExpressions(t *testing.T) {
 	}
 }
 
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+		expected      string
+	}{
+		{"let = 5; let y = 10;", "expected next token to be IDENT, got = instead", "let y = 10;"},
+		{"let x 5; y", "expected next token to be =, got INT instead", "y"},
+		{"let = fn() { a; b; }; let y = 10;", "expected next token to be IDENT, got = instead", "let y = 10;"},
+		{"let f = fn() { let = 1; 2 }; f", "expected next token to be IDENT, got = instead", "let f = fn()2;f"},
+		{"let f = fn() { x + }; f", "no prefix parse function for } found", "let f = fn();f"},
+		{"let f = fn() { let x = }; f", "no prefix parse function for } found", "let f = fn();f"},
+		{"if (x { y }; z", "expected next token to be ), got { instead", "z"},
+		{"switch (x) { y }; z", "expected case or default in switch, got IDENT", "z"},
+		{"switch (x) { default: 1 default: 2 }; z", "switch has more than one default case", "z"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("%q: expected exactly 1 error. got=%q", tt.input, errors)
+			continue
+		}
+
+		if errors[0] != tt.expectedError {
+			t.Errorf("%q: wrong error. expected=%q, got=%q", tt.input, tt.expectedError, errors[0])
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("%q: wrong program. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestUnterminatedBlock(t *testing.T) {
+	tests := []string{
+		"if (true) { let x = 1;",
+		"fn(x) { x",
+		"for (;;) {",
+		"let f = fn() { if (x) { 1 };",
+		"switch (x) { case 1: y",
+	}
+
+	for _, input := range tests {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 || errors[0] != "expected }, got EOF" {
+			t.Errorf("%q: wrong errors. got=%q", input, errors)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	program, err := New(lexer.New("let x = 5; x;")).Parse()
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err)
+	}
+
+	if program.String() != "let x = 5;x" {
+		t.Errorf("program wrong. got=%q", program.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expectedString string
+	}{
+		{"let = 5;", []string{"expected next token to be IDENT, got = instead"}, "expected next token to be IDENT, got = instead"},
+		{
+			"let = 5; let x 1;",
+			[]string{"expected next token to be IDENT, got = instead", "expected next token to be =, got INT instead"},
+			"2 parse errors: expected next token to be IDENT, got = instead; expected next token to be =, got INT instead",
+		},
+	}
+
+	for _, tt := range tests {
+		program, err := New(lexer.New(tt.input)).Parse()
+		if program == nil {
+			t.Errorf("%q: program should be returned even when there are errors", tt.input)
+		}
+
+		parseErrors, ok := err.(ParseErrors)
+		if !ok {
+			t.Errorf("%q: err is not ParseErrors. got=%T (%+v)", tt.input, err, err)
+			continue
+		}
+
+		if len(parseErrors) != len(tt.expectedErrors) {
+			t.Fatalf("%q: wrong number of errors. got=%q, want=%q", tt.input, parseErrors, tt.expectedErrors)
+		}
+
+		for i, msg := range tt.expectedErrors {
+			if parseErrors[i] != msg {
+				t.Errorf("%q: error %d wrong. got=%q, want=%q", tt.input, i, parseErrors[i], msg)
+			}
+		}
+
+		if err.Error() != tt.expectedString {
+			t.Errorf("%q: err.Error() wrong. got=%q, want=%q", tt.input, err.Error(), tt.expectedString)
+		}
+	}
+}
+
 func TestOperatorPrecedenceParsing(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -420,7 +747,7 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		},
 		{
 			"3 + 4; -5 * 5",
-			"(3 + 4)((-5) * 5)",
+			"(3 + 4)(-5 * 5)",
 		},
 		{
 			"5 > 4 == 3 < 4",
@@ -430,6 +757,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"5 < 4 != 3 > 4",
 			"((5 < 4) != (3 > 4))",
 		},
+		{
+			"a <= b == b >= c",
+			"((a <= b) == (b >= c))",
+		},
+		{
+			"a + 1 <= b * 2",
+			"((a + 1) <= (b * 2))",
+		},
 		{
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
@@ -474,6 +809,46 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a == b ? c + 1 : d * 2",
+			"((a == b) ? (c + 1) : (d * 2))",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"a ** b * c",
+			"((a ** b) * c)",
+		},
+		{
+			"-a ** b",
+			"((-a) ** b)",
+		},
+		{
+			"a = b = c + 1",
+			"(a = (b = (c + 1)))",
+		},
+		{
+			"a = b ? c : d",
+			"(a = (b ? c : d))",
+		},
+		{
+			`h["k"] = 5 * 2`,
+			"((h[k]) = (5 * 2))",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -489,55 +864,533 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
-func TestFunctionLiteralParsing(t *testing.T) {
-	input := `fn(x, y) { x + y; }`
+func TestCompoundAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x += 3", "(x += 3)"},
+		{"x -= 3", "(x -= 3)"},
+		{"x *= 2 + 1", "(x *= (2 + 1))"},
+		{"x /= 2", "(x /= 2)"},
+		{"a[f()] += 1", "((a[f()]) += 1)"},
+		{"x += y -= 1", "(x += (y -= 1))"},
+		{"x **= 2", ""},
+	}
 
-	l := lexer.New(input)
-	p := New(l)
-	program := p.ParseProgram()
-	checkParserErrors(t, p)
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
 
-	if len(program.Statements) != 1 {
-		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
-	}
+		if tt.expected == "" {
+			if len(p.Errors()) == 0 {
+				t.Errorf("expected errors for %q, got none", tt.input)
+			}
 
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
-	}
+			continue
+		}
 
-	function, ok := stmt.Expression.(*ast.FunctionLiteral)
-	if !ok {
-		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
-	}
+		checkParserErrors(t, p)
 
-	if len(function.Parameters) != 2 {
-		t.Fatalf("function literal parameters wrong. want 2, got=%d\n", len(function.Parameters))
+		if program.String() != tt.expected {
+			t.Errorf("wrong string for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
 	}
 
-	testLiteralExpression(t, function.Parameters[0], "x")
-	testLiteralExpression(t, function.Parameters[1], "y")
+	program := New(lexer.New("total *= 2")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
 
-	if len(function.Body.Statements) != 1 {
-		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n", len(function.Body.Statements))
+	exp, ok := stmt.Expression.(*ast.CompoundAssignExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.CompoundAssignExpression. got=%T", stmt.Expression)
 	}
 
-	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	if !testIdentifier(t, exp.Target, "total") {
+		return
 	}
 
-	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+	if exp.Operator != "*" {
+		t.Errorf("exp.Operator is not '*'. got=%q", exp.Operator)
+	}
+
+	testIntegerLiteral(t, exp.Value, 2)
 }
 
-func TestFunctionParameterParsing(t *testing.T) {
+func TestPostfixExpression(t *testing.T) {
 	tests := []struct {
-		input          string
-		expectedParams []string
+		input    string
+		expected string
 	}{
-		{input: "fn() {};", expectedParams: []string{}},
-		{input: "fn(x) {};", expectedParams: []string{"x"}},
-		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
+		{"i++", "(i++)"},
+		{"i--", "(i--)"},
+		{"i++ + 1", "((i++) + 1)"},
+		{"-i--", "(-(i--))"},
+		{"a * b++", "(a * (b++))"},
+		{"x = i++", "(x = (i++))"},
+		{"--i", "(-(-i))"},
+		{"a - --b", "(a - (-(-b)))"},
+		{"x-- - y", "((x--) - y)"},
+		{"x - -y", "(x - (-y))"},
+		{"i++; j", "(i++)j"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong string for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("count++")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	exp, ok := stmt.Expression.(*ast.PostfixExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.PostfixExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Target, "count") {
+		return
+	}
+
+	if exp.Operator != "++" {
+		t.Errorf("exp.Operator is not '++'. got=%q", exp.Operator)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"5++", "++ can only be applied to an identifier, got 5"},
+		{"a[0]--", "-- can only be applied to an identifier, got (a[0])"},
+		{"f(i)--", "-- can only be applied to an identifier, got f(i)"},
+		{"x--y", "unexpected y after x--"},
+		{"x++y", "unexpected y after x++"},
+		{"x++(y)", "unexpected ( after x++"},
+		{"x-- 5", "unexpected 5 after x--"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %q. expected first error %q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestAssociativity(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 3 ** 2", "(2 ** (3 ** 2))"},
+		{"2 ** 3 ** 2 ** 1", "(2 ** (3 ** (2 ** 1)))"},
+		{"2 * 3 ** 2 ** 2", "(2 * (3 ** (2 ** 2)))"},
+		{"2 ** 3 * 2", "((2 ** 3) * 2)"},
+		{"a = b = c", "(a = (b = c))"},
+		{"a += b = c", "(a += (b = c))"},
+		{"a - b - c", "((a - b) - c)"},
+		{"a / b / c", "((a / b) / c)"},
+		{"a == b == c", "((a == b) == c)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong grouping for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestTernaryExpression(t *testing.T) {
+	input := `x < y ? x : y`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.TernaryExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.TernaryExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if !testIdentifier(t, exp.Consequence, "x") {
+		return
+	}
+
+	testIdentifier(t, exp.Alternative, "y")
+}
+
+func TestSwitchExpression(t *testing.T) {
+	input := `switch (x) { case 1: a; b; case y + 1, 2, z: c default: }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.SwitchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.SwitchExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Subject, "x") {
+		return
+	}
+
+	if len(exp.Cases) != 2 {
+		t.Fatalf("exp.Cases does not contain 2 cases. got=%d", len(exp.Cases))
+	}
+
+	if len(exp.Cases[0].Values) != 1 || !testLiteralExpression(t, exp.Cases[0].Values[0], 1) {
+		t.Fatalf("first case values wrong. got=%v", exp.Cases[0].Values)
+	}
+
+	if len(exp.Cases[0].Body.Statements) != 2 {
+		t.Errorf("first case does not contain 2 statements. got=%d", len(exp.Cases[0].Body.Statements))
+	}
+
+	if len(exp.Cases[1].Values) != 3 {
+		t.Fatalf("second case does not contain 3 values. got=%d", len(exp.Cases[1].Values))
+	}
+
+	if !testInfixExpression(t, exp.Cases[1].Values[0], "y", "+", 1) {
+		return
+	}
+
+	if !testLiteralExpression(t, exp.Cases[1].Values[1], 2) {
+		return
+	}
+
+	if !testIdentifier(t, exp.Cases[1].Values[2], "z") {
+		return
+	}
+
+	if len(exp.Cases[1].Body.Statements) != 1 {
+		t.Errorf("second case does not contain 1 statement. got=%d", len(exp.Cases[1].Body.Statements))
+	}
+
+	if exp.Default == nil || len(exp.Default.Statements) != 0 {
+		t.Errorf("exp.Default is not an empty block. got=%+v", exp.Default)
+	}
+
+	if expected := "switchx { case 1: ab case (y + 1), 2, z: c default:  }"; exp.String() != expected {
+		t.Errorf("exp.String() wrong. expected=%q, got=%q", expected, exp.String())
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	input := `try { risky(x) } catch (err) { puts(err); 0 }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.TryExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.TryExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Body.Statements) != 1 {
+		t.Errorf("try block does not contain 1 statement. got=%d", len(exp.Body.Statements))
+	}
+
+	if !testIdentifier(t, exp.Parameter, "err") {
+		return
+	}
+
+	if len(exp.Catch.Statements) != 2 {
+		t.Errorf("catch block does not contain 2 statements. got=%d", len(exp.Catch.Statements))
+	}
+
+	if expected := "try risky(x) catch(err) puts(err)0"; exp.String() != expected {
+		t.Errorf("exp.String() wrong. expected=%q, got=%q", expected, exp.String())
+	}
+
+	for _, input := range []string{"try { 1 }", "try { 1 } catch { 2 }", "try { 1 } catch (1) { 2 }"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected errors for %q, got none", input)
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	input := `for (let i = 0; i < 10; i = i + 1) { x }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+
+	if !testLetStatement(t, stmt.Init, "i") {
+		return
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "i", "<", 10) {
+		return
+	}
+
+	post, ok := stmt.Post.(*ast.AssignExpression)
+	if !ok {
+		t.Fatalf("stmt.Post is not ast.AssignExpression. got=%T", stmt.Post)
+	}
+
+	if !testIdentifier(t, post.Target, "i") {
+		return
+	}
+
+	if !testInfixExpression(t, post.Value, "i", "+", 1) {
+		return
+	}
+
+	if len(stmt.Body.Statements) != 1 {
+		t.Fatalf("body does not contain 1 statement. got=%d", len(stmt.Body.Statements))
+	}
+
+	if stmt.String() != "for (let i = 0; (i < 10); (i = (i + 1))) x" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestForStatementOptionalParts(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (;;) { x }", "for (; ; ) x"},
+		{"for (i = 0; ; ) { x }", "for ((i = 0); ; ) x"},
+		{"for (; i < 1;) { x }", "for (; (i < 1); ) x"},
+		{"for (;; i = i + 1) { x }", "for (; ; (i = (i + 1))) x"},
+		{"for (;;) { x }; y", "for (; ; ) xy"},
+		{"for (;;) { break; continue }", "for (; ; ) break;continue;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestDoWhileStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"do { x } while (y)", "do x while (y);"},
+		{"do { x = x + 1; } while (x < 10);", "do (x = (x + 1)) while ((x < 10));"},
+		{"do { break } while (true); y", "do break; while (true);y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if _, ok := program.Statements[0].(*ast.DoWhileStatement); !ok {
+			t.Fatalf("program.Statements[0] is not ast.DoWhileStatement. got=%T", program.Statements[0])
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestForInStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKey   string
+		expectedValue string
+		expected      string
+	}{
+		{"for x in arr { x }", "", "x", "for x in arr x"},
+		{"for k, v in h { v }", "k", "v", "for k, v in h v"},
+		{"for x in [1, 2] { x };", "", "x", "for x in [1, 2] x"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForInStatement. got=%T", program.Statements[0])
+		}
+
+		if tt.expectedKey == "" && stmt.Key != nil {
+			t.Errorf("stmt.Key is not nil. got=%q", stmt.Key)
+		} else if tt.expectedKey != "" {
+			testIdentifier(t, stmt.Key, tt.expectedKey)
+		}
+
+		testIdentifier(t, stmt.Value, tt.expectedValue)
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d\n", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statements. got=%d\n", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d\n", len(macro.Parameters))
+	}
+
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statements. got=%d\n", len(macro.Body.Statements))
+	}
+
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not ast.ExpressionStatement. got=%T", macro.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{input: "fn() {};", expectedParams: []string{}},
+		{input: "fn(x) {};", expectedParams: []string{"x"}},
+		{input: "fn(x, y, z) {};", expectedParams: []string{"x", "y", "z"}},
 	}
 
 	for _, tt := range tests {
@@ -559,6 +1412,62 @@ func TestFunctionParameterParsing(t *testing.T) {
 	}
 }
 
+func TestFunctionDefaultParameters(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedDefaults []string
+		expected         string
+	}{
+		{"fn(x, y = 10) {}", []string{"", "10"}, "fn(x, y = 10)"},
+		{"fn(x = 1 + 2, y) {}", []string{"(1 + 2)", ""}, "fn(x = (1 + 2), y)"},
+		{"fn(x = a, y = x,) {}", []string{"a", "x"}, "fn(x = a, y = x)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Defaults) != len(tt.expectedDefaults) {
+			t.Fatalf("length defaults wrong. want %d, got=%d", len(tt.expectedDefaults), len(function.Defaults))
+		}
+
+		for i, expected := range tt.expectedDefaults {
+			def := function.Defaults[i]
+			if expected == "" {
+				if def != nil {
+					t.Errorf("default %d should be nil. got=%q", i, def.String())
+				}
+
+				continue
+			}
+
+			if def == nil || def.String() != expected {
+				t.Errorf("default %d wrong. want=%q, got=%v", i, expected, def)
+			}
+		}
+
+		if function.String() != tt.expected {
+			t.Errorf("function.String() wrong. want=%q, got=%q", tt.expected, function.String())
+		}
+	}
+}
+
+func TestMacroDefaultParameters(t *testing.T) {
+	l := lexer.New("macro(x = 1) { x }")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 || errors[0] != "macro parameters cannot have default values" {
+		t.Errorf("wrong errors. got=%v", errors)
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := "add(1, 2 * 3, 4 + 5);"
 
@@ -617,6 +1526,40 @@ func TestParsingArrayLiterals(t *testing.T) {
 	testInfixExpression(t, array.Elements[2], 3, "+", 3)
 }
 
+func TestTrailingCommas(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3,]", "[1, 2, 3]"},
+		{"[1,]", "[1]"},
+		{"add(1, 2,)", "add(1, 2)"},
+		{"fn(a, b,) { a }", "fn(a, b)a"},
+		{`{"a": 1,}`, "{a:1}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	for _, input := range []string{"[,]", "add(,)", "fn(,) { 1 }", "{,}", "[1,,]"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func TestParsingIndexExpression(t *testing.T) {
 	input := "myArray[1 + 1]"
 
@@ -641,6 +1584,89 @@ func TestParsingIndexExpression(t *testing.T) {
 	}
 }
 
+func TestParsingMemberExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"person.name", "(person[name])"},
+		{"a.b.c", "((a[b])[c])"},
+		{"a.b[0]", "((a[b])[0])"},
+		{"a.b(1)", "(a[b])(1)"},
+		{"-a.b", "(-(a[b]))"},
+		{"a.b + c.d", "((a[b]) + (c[d]))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong string for %q. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	program := New(lexer.New("person.name")).ParseProgram()
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	key, ok := indexExp.Index.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("index not *ast.StringLiteral. got=%T", indexExp.Index)
+	}
+
+	if key.Value != "name" {
+		t.Errorf("key.Value not %q. got=%q", "name", key.Value)
+	}
+
+	p := New(lexer.New("person.1"))
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected an error for a member that is not an identifier")
+	}
+}
+
+func TestParsingSliceExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"myArray[1:3]", "(myArray[1:3])"},
+		{"myArray[:2]", "(myArray[:2])"},
+		{"myArray[1:]", "(myArray[1:])"},
+		{"myArray[:]", "(myArray[:])"},
+		{"myArray[1 + 1:len(myArray)]", "(myArray[(1 + 1):len(myArray)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		sliceExp, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+		}
+
+		if !testIdentifier(t, sliceExp.Left, "myArray") {
+			return
+		}
+
+		if sliceExp.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, sliceExp.String())
+		}
+	}
+}
+
 func TestParsingHashLiteralsStringKey(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 
@@ -841,3 +1867,104 @@ func testIdentifier(t *testing.T, exp ast.Expression, value string) bool {
 
 	return true
 }
+
+func TestNodePositions(t *testing.T) {
+	input := `let add = fn(a, b) { a + b };
+add(1, 2)[0];
+h.name = -5;
+i++;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	index := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IndexExpression)
+	call := index.Left.(*ast.CallExpression)
+	assign := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.AssignExpression)
+	postfix := program.Statements[3].(*ast.ExpressionStatement).Expression.(*ast.PostfixExpression)
+
+	tests := []struct {
+		node        ast.Node
+		expectedPos string
+		expectedEnd string
+	}{
+		{program, "1:1", "4:4"},
+		{let, "1:1", "1:27"}, // the closing brace isn't stored, so the end is just after b
+		{let.Name, "1:5", "1:8"},
+		{let.Value, "1:11", "1:27"},
+		{let.Value.(*ast.FunctionLiteral).Body.Statements[0], "1:22", "1:27"},
+		{index, "2:1", "2:12"},
+		{call, "2:1", "2:9"},
+		{assign, "3:1", "3:12"},
+		{assign.Target.(*ast.IndexExpression).Index, "3:3", "3:7"},
+		{assign.Value, "3:10", "3:12"},
+		{postfix, "4:1", "4:4"},
+	}
+
+	for i, tt := range tests {
+		if pos := tt.node.Pos().String(); pos != tt.expectedPos {
+			t.Errorf("tests[%d] - %q position wrong. expected=%s, got=%s", i, tt.node.String(), tt.expectedPos, pos)
+		}
+
+		if end := tt.node.End().String(); end != tt.expectedEnd {
+			t.Errorf("tests[%d] - %q end wrong. expected=%s, got=%s", i, tt.node.String(), tt.expectedEnd, end)
+		}
+	}
+}
+
+func TestParsingWithoutSemicolons(t *testing.T) {
+	input := `let add = fn(a, b) {
+	let sum = a + b
+	sum
+}
+let x = add(1,
+	2)
+x++
+if (x > 3) {
+	puts(x)
+} else {
+	x; x
+}
+let h = {
+	"a": 1,
+}`
+
+	expected := []string{
+		"let add = fn(a, b)let sum = (a + b);sum;",
+		"let x = add(1, 2);",
+		"(x++)",
+		"if(x > 3) puts(x)else xx",
+		"let h = {a:1};",
+	}
+
+	l := lexer.New(input)
+	l.SetInsertSemicolons(true)
+
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != len(expected) {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d (%q)", len(expected), len(program.Statements), program.String())
+	}
+
+	for i, e := range expected {
+		if got := program.Statements[i].String(); got != e {
+			t.Errorf("statements[%d] wrong. expected=%q, got=%q", i, e, got)
+		}
+	}
+
+	// Explicit semicolons still work alongside inserted ones.
+	l = lexer.New("let a = 1;\nlet b = 2;\n\na + b;")
+	l.SetInsertSemicolons(true)
+
+	p = New(l)
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Errorf("program.Statements does not contain 3 statements. got=%d (%q)", len(program.Statements), program.String())
+	}
+}
@@ -1,13 +1,35 @@
 package token
 
+import "fmt"
+
 // TokenType represents a type of token, such as an integer or a boolean.
 type TokenType string
 
 // Token represents a small, easily categorizable chunck of the source input.
 // For example, the number "5" is an integer, so it would have Type "INT" and Literal "5"
+// Pos is the position of the first character of the token in the input, and End is the position just after its last.
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position
+	End     Position
+}
+
+// Position is a location in the source input. Lines and columns both start at 1, and columns count characters rather
+// than bytes. Tokens which weren't read from the input, such as those created by the parser, have the zero Position.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// IsValid returns true if the position is a location in the input, rather than the zero Position.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position in the form "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 // Definitions of token types.
@@ -28,10 +50,21 @@ const (
 	MINUS    = "-"
 	BANG     = "!"
 	ASTERISK = "*"
-	SLASH    = "/"
+	POWER    = "**"
+
+	INCREMENT = "++"
+	DECREMENT = "--"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	SLASH           = "/"
 
 	LT     = ">"
 	GT     = "<"
+	LT_EQ  = "<="
+	GT_EQ  = ">="
 	EQ     = "=="
 	NOT_EQ = "!="
 
@@ -39,6 +72,8 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	QUESTION  = "?"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -55,17 +90,45 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	FOR      = "FOR"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	IN       = "IN"
+	MACRO    = "MACRO"
+	CONST    = "CONST"
+	DO       = "DO"
+	WHILE    = "WHILE"
+	NULL     = "NULL"
+	SWITCH   = "SWITCH"
+	CASE     = "CASE"
+	DEFAULT  = "DEFAULT"
+	TRY      = "TRY"
+	CATCH    = "CATCH"
 )
 
 // keywords maps keyword names to their TokenType values.
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"for":      FOR,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"in":       IN,
+	"macro":    MACRO,
+	"const":    CONST,
+	"do":       DO,
+	"while":    WHILE,
+	"null":     NULL,
+	"switch":   SWITCH,
+	"case":     CASE,
+	"default":  DEFAULT,
+	"try":      TRY,
+	"catch":    CATCH,
 }
 
 // LookupIdent returns a TokenType for the name of an identifier.